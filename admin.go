@@ -0,0 +1,386 @@
+package main
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"marianapparitions/model"
+	"marianapparitions/repository"
+	"marianapparitions/viewmodel"
+)
+
+// newAdminHandler serves the editor, which replaces the Django admin of
+// data_management/: the JSON API under /api/ (see api.go) and its pages
+// under /admin/, both behind the admin account (see config.AdminConfig).
+func newAdminHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/events", apiCreateEvent)
+	mux.HandleFunc("GET /api/events/{id}", apiGetEvent)
+	mux.HandleFunc("PUT /api/events/{id}", apiUpdateEvent)
+	mux.HandleFunc("DELETE /api/events/{id}", apiDeleteEvent)
+	mux.HandleFunc("POST /api/events/{id}/blocks", apiCreateBlock)
+	mux.HandleFunc("PUT /api/events/{id}/blocks/order", apiReorderBlocks)
+	mux.HandleFunc("PUT /api/events/{id}/blocks/{childID}", apiUpdateBlock)
+	mux.HandleFunc("DELETE /api/events/{id}/blocks/{childID}", apiDeleteBlock)
+	mux.HandleFunc("POST /api/events/{id}/requests", apiCreateRequest)
+	mux.HandleFunc("PUT /api/events/{id}/requests/{childID}", apiUpdateRequest)
+	mux.HandleFunc("DELETE /api/events/{id}/requests/{childID}", apiDeleteRequest)
+	mux.HandleFunc("POST /api/events/{id}/sources", apiCreateSource)
+	mux.HandleFunc("PUT /api/events/{id}/sources/{childID}", apiUpdateSource)
+	mux.HandleFunc("DELETE /api/events/{id}/sources/{childID}", apiDeleteSource)
+
+	// HTML forms can only GET and POST, so updates and deletes are POSTs
+	mux.HandleFunc("GET /admin/{$}", adminIndex)
+	mux.HandleFunc("GET /admin/events/new", adminNewEvent)
+	mux.HandleFunc("POST /admin/events", adminCreateEvent)
+	mux.HandleFunc("GET /admin/events/{id}", adminEditEvent)
+	mux.HandleFunc("POST /admin/events/{id}", adminUpdateEvent)
+	mux.HandleFunc("POST /admin/events/{id}/delete", adminDeleteEvent)
+	mux.HandleFunc("POST /admin/events/{id}/blocks", adminCreateBlock)
+	mux.HandleFunc("POST /admin/events/{id}/blocks/{childID}", adminUpdateBlock)
+	mux.HandleFunc("POST /admin/events/{id}/blocks/{childID}/move", adminMoveBlock)
+	mux.HandleFunc("POST /admin/events/{id}/blocks/{childID}/delete", adminDeleteBlock)
+	mux.HandleFunc("POST /admin/events/{id}/requests", adminCreateRequest)
+	mux.HandleFunc("POST /admin/events/{id}/requests/{childID}", adminUpdateRequest)
+	mux.HandleFunc("POST /admin/events/{id}/requests/{childID}/delete", adminDeleteRequest)
+	mux.HandleFunc("POST /admin/events/{id}/sources", adminCreateSource)
+	mux.HandleFunc("POST /admin/events/{id}/sources/{childID}", adminUpdateSource)
+	mux.HandleFunc("POST /admin/events/{id}/sources/{childID}/delete", adminDeleteSource)
+
+	// Browsers send the Basic credentials along with cross-site requests too
+	return requireAdmin(http.NewCrossOriginProtection().Handler(mux))
+}
+
+// requireAdmin checks the Basic credentials of the admin account. The
+// editor doesn't exist (404) while no password is configured.
+func requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Admin.Password == "" {
+			http.NotFound(w, r)
+			return
+		}
+		user, password, ok := r.BasicAuth()
+		if !ok || !equalSecret(user, cfg.Admin.User) || !equalSecret(password, cfg.Admin.Password) {
+			if ok {
+				slog.WarnContext(r.Context(), "Failed admin login", "user", user, "remote_addr", r.RemoteAddr)
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="Marian Apparitions editor", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			slog.InfoContext(r.Context(), "Edit", "user", user, "method", r.Method, "path", r.URL.Path)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func equalSecret(given, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(want)) == 1
+}
+
+func adminIndex(w http.ResponseWriter, r *http.Request) {
+	events, err := repository.GetAllEventsContext(r.Context(), db)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	coll := newCollator(requestLocale(r))
+	slices.SortFunc(events, func(a, b model.Event) int {
+		return coll.CompareString(a.Name, b.Name)
+	})
+	renderTemplate(w, r, "admin_index.html", &viewmodel.AdminIndexViewModel{Events: events})
+}
+
+func adminNewEvent(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, r, "admin_event.html", &viewmodel.AdminEventViewModel{})
+}
+
+func adminCreateEvent(w http.ResponseWriter, r *http.Request) {
+	e := eventFromForm(r)
+	err := repository.CreateEventContext(r.Context(), db, &e)
+	var invalid model.ValidationError
+	if errors.As(err, &invalid) {
+		renderAdminEvent(w, r, http.StatusUnprocessableEntity, &viewmodel.AdminEventViewModel{Event: e, Errors: invalid, ErrorsAbout: "The apparition"})
+		return
+	}
+	adminSaved(w, r, err, fmt.Sprintf("/admin/events/%d", e.ID))
+}
+
+func adminEditEvent(w http.ResponseWriter, r *http.Request) {
+	e, ok := adminEvent(w, r)
+	if !ok {
+		return
+	}
+	renderAdminEvent(w, r, http.StatusOK, &viewmodel.AdminEventViewModel{Event: e})
+}
+
+func adminUpdateEvent(w http.ResponseWriter, r *http.Request) {
+	e := eventFromForm(r)
+	e.ID, _ = strconv.Atoi(r.PathValue("id"))
+	err := repository.UpdateEventContext(r.Context(), db, &e)
+	adminChildSaved(w, r, err, "The apparition", "", func(vm *viewmodel.AdminEventViewModel) {
+		e.Requests, e.Blocks, e.Sources = vm.Event.Requests, vm.Event.Blocks, vm.Event.Sources
+		vm.Event = e
+	})
+}
+
+func adminDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	adminSaved(w, r, repository.DeleteEventContext(r.Context(), db, id), "/admin/")
+}
+
+func adminCreateBlock(w http.ResponseWriter, r *http.Request) {
+	e, ok := adminEvent(w, r)
+	if !ok {
+		return
+	}
+	b := blockFromForm(r)
+	b.EventID = e.ID
+	err := repository.CreateBlockContext(r.Context(), db, &b)
+	adminChildSaved(w, r, err, "The new block", "blocks", func(vm *viewmodel.AdminEventViewModel) {
+		vm.NewBlock = b
+	})
+}
+
+func adminUpdateBlock(w http.ResponseWriter, r *http.Request) {
+	b := blockFromForm(r)
+	b.EventID, b.ID = pathIDs(r)
+	err := repository.UpdateBlockContext(r.Context(), db, &b)
+	adminChildSaved(w, r, err, fmt.Sprintf("Block #%d", b.ID), fmt.Sprintf("block-%d", b.ID), keepBlock(b))
+}
+
+// adminMoveBlock swaps a block with the previous one (direction=up) or the
+// next one (direction=down). The move buttons are in the block's form: its
+// fields are saved first, so that moving doesn't lose the edits.
+func adminMoveBlock(w http.ResponseWriter, r *http.Request) {
+	eventID, id := pathIDs(r)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := r.PostForm["content"]; ok {
+		b := blockFromForm(r)
+		b.EventID, b.ID = eventID, id
+		if err := repository.UpdateBlockContext(r.Context(), db, &b); err != nil {
+			adminChildSaved(w, r, err, fmt.Sprintf("Block #%d", b.ID), "", keepBlock(b))
+			return
+		}
+	}
+
+	blocks, err := repository.GetBlocksByEventIDContext(r.Context(), db, eventID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var ids []int
+	for _, b := range blocks {
+		ids = append(ids, b.ID)
+	}
+	i := slices.Index(ids, id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	j := i + 1
+	if r.PostFormValue("direction") == "up" {
+		j = i - 1
+	}
+	if j >= 0 && j < len(ids) {
+		ids[i], ids[j] = ids[j], ids[i]
+		err = repository.ReorderBlocksContext(r.Context(), db, eventID, ids)
+	}
+	adminSaved(w, r, err, fmt.Sprintf("/admin/events/%d#block-%d", eventID, id))
+}
+
+// keepBlock shows the submitted b instead of the saved one.
+func keepBlock(b model.EventBlock) func(*viewmodel.AdminEventViewModel) {
+	return func(vm *viewmodel.AdminEventViewModel) {
+		for i := range vm.Event.Blocks {
+			if vm.Event.Blocks[i].ID == b.ID {
+				vm.Event.Blocks[i] = b
+			}
+		}
+	}
+}
+
+func adminDeleteBlock(w http.ResponseWriter, r *http.Request) {
+	eventID, id := pathIDs(r)
+	adminSaved(w, r, repository.DeleteBlockContext(r.Context(), db, eventID, id), fmt.Sprintf("/admin/events/%d#blocks", eventID))
+}
+
+func adminCreateRequest(w http.ResponseWriter, r *http.Request) {
+	e, ok := adminEvent(w, r)
+	if !ok {
+		return
+	}
+	req := model.Request{EventID: e.ID, Request: strings.TrimSpace(r.PostFormValue("request"))}
+	err := repository.CreateRequestContext(r.Context(), db, &req)
+	adminChildSaved(w, r, err, "The new request", "requests", func(vm *viewmodel.AdminEventViewModel) {
+		vm.NewRequest = req
+	})
+}
+
+func adminUpdateRequest(w http.ResponseWriter, r *http.Request) {
+	req := model.Request{Request: strings.TrimSpace(r.PostFormValue("request"))}
+	req.EventID, req.ID = pathIDs(r)
+	err := repository.UpdateRequestContext(r.Context(), db, &req)
+	adminChildSaved(w, r, err, fmt.Sprintf("Request #%d", req.ID), "requests", func(vm *viewmodel.AdminEventViewModel) {
+		for i := range vm.Event.Requests {
+			if vm.Event.Requests[i].ID == req.ID {
+				vm.Event.Requests[i] = req
+			}
+		}
+	})
+}
+
+func adminDeleteRequest(w http.ResponseWriter, r *http.Request) {
+	eventID, id := pathIDs(r)
+	adminSaved(w, r, repository.DeleteRequestContext(r.Context(), db, eventID, id), fmt.Sprintf("/admin/events/%d#requests", eventID))
+}
+
+func adminCreateSource(w http.ResponseWriter, r *http.Request) {
+	e, ok := adminEvent(w, r)
+	if !ok {
+		return
+	}
+	s := sourceFromForm(r)
+	s.EventID = e.ID
+	err := repository.CreateSourceContext(r.Context(), db, &s)
+	adminChildSaved(w, r, err, "The new source", "sources", func(vm *viewmodel.AdminEventViewModel) {
+		vm.NewSource = s
+	})
+}
+
+func adminUpdateSource(w http.ResponseWriter, r *http.Request) {
+	s := sourceFromForm(r)
+	s.EventID, s.ID = pathIDs(r)
+	err := repository.UpdateSourceContext(r.Context(), db, &s)
+	adminChildSaved(w, r, err, fmt.Sprintf("Source #%d", s.ID), "sources", func(vm *viewmodel.AdminEventViewModel) {
+		for i := range vm.Event.Sources {
+			if vm.Event.Sources[i].ID == s.ID {
+				vm.Event.Sources[i] = s
+			}
+		}
+	})
+}
+
+func adminDeleteSource(w http.ResponseWriter, r *http.Request) {
+	eventID, id := pathIDs(r)
+	adminSaved(w, r, repository.DeleteSourceContext(r.Context(), db, eventID, id), fmt.Sprintf("/admin/events/%d#sources", eventID))
+}
+
+// adminEvent loads the event of the {id} path segment, or answers 404.
+func adminEvent(w http.ResponseWriter, r *http.Request) (model.Event, bool) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	e, err := repository.GetEventByIDContext(r.Context(), db, id)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return e, false
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return e, false
+	}
+	return e, true
+}
+
+// adminSaved redirects to next after a successful change, so that
+// reloading the page doesn't submit the form again.
+func adminSaved(w http.ResponseWriter, r *http.Request, err error, next string) {
+	var invalid model.ValidationError
+	switch {
+	case err == nil:
+		indexEvents.Invalidate()
+		http.Redirect(w, r, next, http.StatusSeeOther)
+	case errors.As(err, &invalid):
+		http.Error(w, invalid.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, sql.ErrNoRows):
+		http.NotFound(w, r)
+	default:
+		slog.ErrorContext(r.Context(), "Failed to save", "path", r.URL.Path, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// adminChildSaved redirects back to the event's page, at anchor, after a
+// successful change. An invalid record is shown again on the page with its
+// errors: keep puts what was submitted in the page's view model.
+func adminChildSaved(w http.ResponseWriter, r *http.Request, err error, about, anchor string, keep func(*viewmodel.AdminEventViewModel)) {
+	var invalid model.ValidationError
+	if !errors.As(err, &invalid) {
+		next := "/admin/events/" + r.PathValue("id")
+		if anchor != "" {
+			next += "#" + anchor
+		}
+		adminSaved(w, r, err, next)
+		return
+	}
+
+	e, ok := adminEvent(w, r)
+	if !ok {
+		return
+	}
+	vm := &viewmodel.AdminEventViewModel{Event: e, Errors: invalid, ErrorsAbout: about}
+	keep(vm)
+	renderAdminEvent(w, r, http.StatusUnprocessableEntity, vm)
+}
+
+func renderAdminEvent(w http.ResponseWriter, r *http.Request, status int, vm *viewmodel.AdminEventViewModel) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	renderTemplate(w, r, "admin_event.html", vm)
+}
+
+// eventFromForm reads the event form. The slug is made from the name when
+// left empty.
+func eventFromForm(r *http.Request) model.Event {
+	e := model.Event{
+		Name:                  strings.TrimSpace(r.PostFormValue("name")),
+		SlugDB:                strings.TrimSpace(r.PostFormValue("slug")),
+		Category:              strings.TrimSpace(r.PostFormValue("category")),
+		Years:                 strings.TrimSpace(r.PostFormValue("years")),
+		Country:               strings.TrimSpace(r.PostFormValue("country")),
+		Description:           formText(r, "description"),
+		WikipediaSectionTitle: strings.TrimSpace(r.PostFormValue("wikipedia_section_title")),
+		ImageFilename:         strings.TrimSpace(r.PostFormValue("image_filename")),
+	}
+	e.SlugDB = e.Slug()
+	return e
+}
+
+func blockFromForm(r *http.Request) model.EventBlock {
+	b := model.EventBlock{
+		Title:             strings.TrimSpace(r.PostFormValue("title")),
+		Content:           formText(r, "content"),
+		Language:          strings.TrimSpace(r.PostFormValue("language")),
+		ChurchAuthority:   strings.TrimSpace(r.PostFormValue("church_authority")),
+		AuthorityPosition: r.PostFormValue("authority_position"),
+	}
+	b.Ordering, _ = strconv.Atoi(r.PostFormValue("ordering"))
+	return b
+}
+
+func sourceFromForm(r *http.Request) model.Source {
+	return model.Source{
+		URL:        strings.TrimSpace(r.PostFormValue("url")),
+		Title:      strings.TrimSpace(r.PostFormValue("title")),
+		Author:     strings.TrimSpace(r.PostFormValue("author")),
+		Publisher:  strings.TrimSpace(r.PostFormValue("publisher")),
+		AccessedOn: strings.TrimSpace(r.PostFormValue("accessed_on")),
+	}
+}
+
+// formText reads a textarea, whose line breaks browsers send as CRLF.
+func formText(r *http.Request, name string) string {
+	return strings.TrimSpace(strings.ReplaceAll(r.PostFormValue(name), "\r\n", "\n"))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"marianapparitions/config"
+	"marianapparitions/model"
+	"marianapparitions/repository"
+)
+
+// openTestDB points db at an empty database for the duration of the test.
+func openTestDB(t *testing.T) {
+	t.Helper()
+	saved := db
+	var err error
+	db, err = sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		db = saved
+	})
+	if err := initDB(false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveEditor sends a request to the editor as the admin.
+func serveEditor(t *testing.T, method, target, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	t.Helper()
+	defer func(saved config.Config) { cfg = saved }(cfg)
+	cfg.Admin = config.AdminConfig{User: "admin", Password: "correct-horse-battery"}
+
+	r := httptest.NewRequest(method, target, body)
+	r.SetBasicAuth("admin", "correct-horse-battery")
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	newAdminHandler().ServeHTTP(w, r)
+	return w
+}
+
+func TestRequireAdmin(t *testing.T) {
+	defer func(saved config.Config) { cfg = saved }(cfg)
+	handler := requireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	status := func(user, password string) int {
+		r := httptest.NewRequest("GET", "/admin/", nil)
+		if user != "" {
+			r.SetBasicAuth(user, password)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	cfg.Admin = config.AdminConfig{User: "admin"}
+	if got := status("admin", ""); got != http.StatusNotFound {
+		t.Errorf("without a password configured: %d, want 404", got)
+	}
+
+	cfg.Admin.Password = "correct-horse-battery"
+	for _, tt := range []struct {
+		user, password string
+		want           int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"admin", "wrong", http.StatusUnauthorized},
+		{"editor", "correct-horse-battery", http.StatusUnauthorized},
+		{"admin", "correct-horse-battery", http.StatusOK},
+	} {
+		if got := status(tt.user, tt.password); got != tt.want {
+			t.Errorf("%s:%s: %d, want %d", tt.user, tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestAdminMoveBlockSavesFields(t *testing.T) {
+	openTestDB(t)
+	ctx := context.Background()
+	e := model.Event{Name: "Our Lady of Knock", SlugDB: "knock", Years: "1879"}
+	if err := repository.CreateEventContext(ctx, db, &e); err != nil {
+		t.Fatal(err)
+	}
+	first := model.EventBlock{EventID: e.ID, Title: "Apparition", Content: "Fifteen villagers saw the apparition."}
+	second := model.EventBlock{EventID: e.ID, Title: "Investigation", Content: "Two commissions heard the witnesses."}
+	for _, b := range []*model.EventBlock{&first, &second} {
+		if err := repository.CreateBlockContext(ctx, db, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	form := url.Values{"direction": {"down"}, "title": {"The apparition"}, "content": {"Fifteen villagers, in the rain."}, "language": {"en"}}
+	w := serveEditor(t, "POST", fmt.Sprintf("/admin/events/%d/blocks/%d/move", e.ID, first.ID), "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("status %d, want 303: %s", w.Code, w.Body)
+	}
+
+	blocks, err := repository.GetBlocksByEventIDContext(ctx, db, e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0].ID != second.ID || blocks[1].ID != first.ID {
+		t.Fatalf("blocks = %+v, want the first block moved down", blocks)
+	}
+	if moved := blocks[1]; moved.Title != "The apparition" || moved.Content != "Fifteen villagers, in the rain." {
+		t.Errorf("moved block = %+v, want the submitted fields saved", moved)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"marianapparitions/model"
+	"marianapparitions/repository"
+	"marianapparitions/viewmodel"
+)
+
+// The editing API, under /api/ (see newAdminHandler). Records are sent and
+// returned as the JSON of the model, e.g.
+//
+//	curl -u admin:$ADMIN_PASSWORD -X PUT -d '{"request": "Pray the Rosary"}' /api/events/3/requests/12
+//
+// Events are saved without their requests, blocks and sources, which have
+// their own endpoints, and the fields computed for the pages are ignored.
+// Updates only change the fields given, the others keep their value.
+// Invalid records are answered with 422 and
+// {"errors": [{"field": ..., "message": ...}]}.
+
+func apiGetEvent(w http.ResponseWriter, r *http.Request) {
+	e, ok := apiEvent(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, viewmodel.NewEventVM(&e))
+}
+
+func apiCreateEvent(w http.ResponseWriter, r *http.Request) {
+	var e model.Event
+	if !decodeJSON(w, r, &e) {
+		return
+	}
+	e.ID = 0
+	e.SlugDB = e.Slug() // Made from the name when empty
+	err := repository.CreateEventContext(r.Context(), db, &e)
+	if err == nil {
+		w.Header().Set("Location", fmt.Sprintf("/api/events/%d", e.ID))
+	}
+	apiRespond(w, r, http.StatusCreated, err, &e)
+}
+
+// apiUpdateEvent changes the fields given, the others keep their value.
+// In particular, the slug (and so the URL) only changes when it is given.
+func apiUpdateEvent(w http.ResponseWriter, r *http.Request) {
+	e, ok := apiEvent(w, r)
+	if !ok || !decodeJSON(w, r, &e) {
+		return
+	}
+	e.ID, _ = strconv.Atoi(r.PathValue("id"))
+	apiRespond(w, r, http.StatusOK, repository.UpdateEventContext(r.Context(), db, &e), &e)
+}
+
+func apiDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	apiRespond(w, r, http.StatusNoContent, repository.DeleteEventContext(r.Context(), db, id), nil)
+}
+
+func apiCreateBlock(w http.ResponseWriter, r *http.Request) {
+	var b model.EventBlock
+	e, ok := apiEvent(w, r)
+	if !ok || !decodeJSON(w, r, &b) {
+		return
+	}
+	b.EventID = e.ID
+	apiRespond(w, r, http.StatusCreated, repository.CreateBlockContext(r.Context(), db, &b), &b)
+}
+
+func apiUpdateBlock(w http.ResponseWriter, r *http.Request) {
+	e, ok := apiEvent(w, r)
+	if !ok {
+		return
+	}
+	_, id := pathIDs(r)
+	i := slices.IndexFunc(e.Blocks, func(b model.EventBlock) bool { return b.ID == id })
+	if i < 0 {
+		apiRespond(w, r, http.StatusOK, sql.ErrNoRows, nil)
+		return
+	}
+	b := e.Blocks[i]
+	if !decodeJSON(w, r, &b) {
+		return
+	}
+	b.EventID, b.ID = e.ID, id
+	apiRespond(w, r, http.StatusOK, repository.UpdateBlockContext(r.Context(), db, &b), &b)
+}
+
+func apiDeleteBlock(w http.ResponseWriter, r *http.Request) {
+	eventID, id := pathIDs(r)
+	apiRespond(w, r, http.StatusNoContent, repository.DeleteBlockContext(r.Context(), db, eventID, id), nil)
+}
+
+// apiReorderBlocks takes {"block_ids": [...]}, the IDs of all the event's
+// blocks in their new order.
+func apiReorderBlocks(w http.ResponseWriter, r *http.Request) {
+	var order struct {
+		BlockIDs []int `json:"block_ids"`
+	}
+	e, ok := apiEvent(w, r)
+	if !ok || !decodeJSON(w, r, &order) {
+		return
+	}
+	err := repository.ReorderBlocksContext(r.Context(), db, e.ID, order.BlockIDs)
+	if err != nil {
+		apiRespond(w, r, http.StatusOK, err, nil)
+		return
+	}
+	blocks, err := repository.GetBlocksByEventIDContext(r.Context(), db, e.ID)
+	apiRespond(w, r, http.StatusOK, err, blocks)
+}
+
+func apiCreateRequest(w http.ResponseWriter, r *http.Request) {
+	var req model.Request
+	e, ok := apiEvent(w, r)
+	if !ok || !decodeJSON(w, r, &req) {
+		return
+	}
+	req.EventID = e.ID
+	apiRespond(w, r, http.StatusCreated, repository.CreateRequestContext(r.Context(), db, &req), &req)
+}
+
+func apiUpdateRequest(w http.ResponseWriter, r *http.Request) {
+	e, ok := apiEvent(w, r)
+	if !ok {
+		return
+	}
+	_, id := pathIDs(r)
+	i := slices.IndexFunc(e.Requests, func(req model.Request) bool { return req.ID == id })
+	if i < 0 {
+		apiRespond(w, r, http.StatusOK, sql.ErrNoRows, nil)
+		return
+	}
+	req := e.Requests[i]
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	req.EventID, req.ID = e.ID, id
+	apiRespond(w, r, http.StatusOK, repository.UpdateRequestContext(r.Context(), db, &req), &req)
+}
+
+func apiDeleteRequest(w http.ResponseWriter, r *http.Request) {
+	eventID, id := pathIDs(r)
+	apiRespond(w, r, http.StatusNoContent, repository.DeleteRequestContext(r.Context(), db, eventID, id), nil)
+}
+
+func apiCreateSource(w http.ResponseWriter, r *http.Request) {
+	var s model.Source
+	e, ok := apiEvent(w, r)
+	if !ok || !decodeJSON(w, r, &s) {
+		return
+	}
+	s.EventID = e.ID
+	apiRespond(w, r, http.StatusCreated, repository.CreateSourceContext(r.Context(), db, &s), &s)
+}
+
+func apiUpdateSource(w http.ResponseWriter, r *http.Request) {
+	e, ok := apiEvent(w, r)
+	if !ok {
+		return
+	}
+	_, id := pathIDs(r)
+	i := slices.IndexFunc(e.Sources, func(s model.Source) bool { return s.ID == id })
+	if i < 0 {
+		apiRespond(w, r, http.StatusOK, sql.ErrNoRows, nil)
+		return
+	}
+	s := e.Sources[i]
+	if !decodeJSON(w, r, &s) {
+		return
+	}
+	s.EventID, s.ID = e.ID, id
+	apiRespond(w, r, http.StatusOK, repository.UpdateSourceContext(r.Context(), db, &s), &s)
+}
+
+func apiDeleteSource(w http.ResponseWriter, r *http.Request) {
+	eventID, id := pathIDs(r)
+	apiRespond(w, r, http.StatusNoContent, repository.DeleteSourceContext(r.Context(), db, eventID, id), nil)
+}
+
+// apiEvent loads the event of the {id} path segment, or answers 404.
+func apiEvent(w http.ResponseWriter, r *http.Request) (model.Event, bool) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	e, err := repository.GetEventByIDContext(r.Context(), db, id)
+	if err != nil {
+		apiRespond(w, r, http.StatusOK, err, nil)
+		return e, false
+	}
+	return e, true
+}
+
+// pathIDs returns the {id} of the event and the {childID} of its block,
+// request or source. Invalid IDs are 0, which matches nothing.
+func pathIDs(r *http.Request) (eventID, id int) {
+	eventID, _ = strconv.Atoi(r.PathValue("id"))
+	id, _ = strconv.Atoi(r.PathValue("childID"))
+	return eventID, id
+}
+
+// maxAPIBody bounds the JSON bodies, blocks being the longest records.
+const maxAPIBody = 1 << 20
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, dest any) bool {
+	// Unknown fields are ignored, so that what GET returns (with its
+	// computed fields) can be sent back
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody)).Decode(dest); err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "Invalid JSON: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// apiRespond answers with data and status if err is nil, or with the error.
+// Successful changes invalidate the events cache.
+func apiRespond(w http.ResponseWriter, r *http.Request, status int, err error, data any) {
+	var invalid model.ValidationError
+	switch {
+	case err == nil:
+		if r.Method != http.MethodGet {
+			indexEvents.Invalidate()
+		}
+		if data == nil {
+			w.WriteHeader(status)
+			return
+		}
+		writeJSON(w, r, status, data)
+	case errors.As(err, &invalid):
+		writeJSON(w, r, http.StatusUnprocessableEntity, map[string]any{"errors": invalid})
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, r, http.StatusNotFound, map[string]string{"error": "Not found"})
+	default:
+		slog.ErrorContext(r.Context(), "Failed to save", "method", r.Method, "path", r.URL.Path, "error", err)
+		writeJSON(w, r, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"marianapparitions/model"
+	"marianapparitions/repository"
+)
+
+func TestAPIPartialUpdates(t *testing.T) {
+	openTestDB(t)
+	ctx := context.Background()
+	e := model.Event{Name: "Our Lady of Knock", SlugDB: "knock", Category: "Apparition", Years: "1879", Country: "Ireland"}
+	if err := repository.CreateEventContext(ctx, db, &e); err != nil {
+		t.Fatal(err)
+	}
+	b := model.EventBlock{EventID: e.ID, Title: "Apparition", Content: "Fifteen villagers saw the apparition.", Language: "en", ChurchAuthority: "Catholic Church", AuthorityPosition: "approved"}
+	if err := repository.CreateBlockContext(ctx, db, &b); err != nil {
+		t.Fatal(err)
+	}
+	req := model.Request{EventID: e.ID, Request: "Pray the Rosary"}
+	if err := repository.CreateRequestContext(ctx, db, &req); err != nil {
+		t.Fatal(err)
+	}
+	s := model.Source{EventID: e.ID, URL: "https://knockshrine.ie", Title: "Knock Shrine", Publisher: "Knock Shrine"}
+	if err := repository.CreateSourceContext(ctx, db, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, put := range []struct{ path, body string }{
+		{fmt.Sprintf("/api/events/%d", e.ID), `{"country": "Éire"}`},
+		{fmt.Sprintf("/api/events/%d/blocks/%d", e.ID, b.ID), `{"title": "The apparition"}`},
+		{fmt.Sprintf("/api/events/%d/requests/%d", e.ID, req.ID), `{}`},
+		{fmt.Sprintf("/api/events/%d/sources/%d", e.ID, s.ID), `{"author": "Tom Neary"}`},
+	} {
+		if w := serveEditor(t, "PUT", put.path, "application/json", strings.NewReader(put.body)); w.Code != http.StatusOK {
+			t.Fatalf("PUT %s: status %d, want 200: %s", put.path, w.Code, w.Body)
+		}
+	}
+
+	got, err := repository.GetEventByIDContext(ctx, db, e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Country = "Éire"
+	if got.Name != e.Name || got.SlugDB != e.SlugDB || got.Category != e.Category || got.Years != e.Years || got.Country != e.Country {
+		t.Errorf("event = %+v, want only the country changed", got)
+	}
+	b.Title = "The apparition"
+	if len(got.Blocks) != 1 || got.Blocks[0].Title != b.Title || got.Blocks[0].Content != b.Content || got.Blocks[0].AuthorityPosition != b.AuthorityPosition || got.Blocks[0].ChurchAuthority != b.ChurchAuthority {
+		t.Errorf("blocks = %+v, want only the title changed", got.Blocks)
+	}
+	if len(got.Requests) != 1 || got.Requests[0].Request != req.Request {
+		t.Errorf("requests = %+v, want the request unchanged", got.Requests)
+	}
+	s.Author = "Tom Neary"
+	if len(got.Sources) != 1 || got.Sources[0] != s {
+		t.Errorf("sources = %+v, want only the author changed", got.Sources)
+	}
+}
@@ -0,0 +1,160 @@
+// Package assets serves static files. It refuses directory listings and
+// dotfiles, and supports content-hashed ("fingerprinted") URLs that can be
+// cached forever.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const hashLen = 10
+
+// Server serves the files of fsys. To prevent symlinks from escaping the
+// static directory, fsys should come from os.Root.FS (or embed.FS).
+type Server struct {
+	fsys   fs.FS
+	prefix string // URL prefix the server is mounted on, e.g. "/static/"
+	// MaxAge is how long browsers may cache fingerprinted files
+	MaxAge time.Duration
+
+	mu     sync.Mutex
+	hashes map[string]fileHash
+}
+
+type fileHash struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// New returns a Server for fsys. It must be mounted on prefix with the
+// prefix stripped, e.g. http.StripPrefix("/static/", s).
+func New(fsys fs.FS, prefix string) *Server {
+	return &Server{fsys: fsys, prefix: prefix, MaxAge: 365 * 24 * time.Hour, hashes: make(map[string]fileHash)}
+}
+
+// URL returns the fingerprinted URL of a file, e.g. "css/styles.css" ->
+// "/static/css/styles.1a2b3c4d5e.css". If the file can't be read, the plain
+// URL is returned. Meant to be used as the "asset" template function.
+func (s *Server) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	hash, err := s.hash(name)
+	if err != nil {
+		return s.prefix + name
+	}
+
+	ext := path.Ext(name)
+	return s.prefix + strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || hasDotSegment(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	immutable := false
+	if orig, hash, ok := splitFingerprint(name); ok {
+		if current, err := s.hash(orig); err == nil {
+			name = orig
+			// A stale fingerprint (e.g. from a cached page after a deploy)
+			// still gets the current file, just not cached for long
+			immutable = current == hash
+		}
+	}
+
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil || info.IsDir() {
+		// No directory listings
+		http.NotFound(w, r)
+		return
+	}
+
+	// Embedded files have no modification time, so revalidation relies on the ETag
+	if hash, err := s.hash(name); err == nil {
+		w.Header().Set("ETag", `"`+hash+`"`)
+	}
+	if immutable {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(s.MaxAge.Seconds()))+", immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeFileFS(w, r, s.fsys, name)
+}
+
+// hash returns the truncated SHA-256 of a file's content. Hashes are cached
+// until the file's size or modification time changes.
+func (s *Server) hash(name string) (string, error) {
+	if hasDotSegment(name) {
+		return "", fs.ErrNotExist
+	}
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fs.ErrNotExist
+	}
+
+	s.mu.Lock()
+	cached, ok := s.hashes[name]
+	s.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.hash, nil
+	}
+
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))[:hashLen]
+
+	s.mu.Lock()
+	s.hashes[name] = fileHash{modTime: info.ModTime(), size: info.Size(), hash: sum}
+	s.mu.Unlock()
+	return sum, nil
+}
+
+// splitFingerprint splits "css/styles.1a2b3c4d5e.css" into "css/styles.css"
+// and "1a2b3c4d5e".
+func splitFingerprint(name string) (orig, hash string, ok bool) {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	dot := strings.LastIndex(stem, ".")
+	if dot < 0 || strings.Contains(stem[dot:], "/") {
+		return "", "", false
+	}
+	hash = stem[dot+1:]
+	if len(hash) != hashLen {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", "", false
+	}
+	return stem[:dot] + ext, hash, true
+}
+
+// hasDotSegment reports whether any path segment is hidden (".git", ".env"...).
+func hasDotSegment(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func serve(s *Server, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	http.StripPrefix("/static/", s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	s := New(fstest.MapFS{
+		"css/styles.css": {Data: []byte("body {}")},
+		".env":           {Data: []byte("SECRET=1")},
+		"css/.hidden":    {Data: []byte("x")},
+	}, "/static/")
+
+	url := s.URL("css/styles.css")
+	if !strings.HasPrefix(url, "/static/css/styles.") || url == "/static/css/styles.css" {
+		t.Fatalf("URL() = %q, want a fingerprinted URL", url)
+	}
+
+	rec := serve(s, url)
+	if rec.Code != http.StatusOK || rec.Body.String() != "body {}" {
+		t.Errorf("fingerprinted URL: got %d %q", rec.Code, rec.Body.String())
+	}
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("fingerprinted URL: Cache-Control = %q", cc)
+	}
+
+	rec = serve(s, "/static/css/styles.css")
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("plain URL: got %d, Cache-Control %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+
+	rec = serve(s, "/static/css/styles.0000000000.css")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("stale fingerprint: got %d, Cache-Control %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+
+	for _, target := range []string{"/static/.env", "/static/css/.hidden", "/static/css/", "/static/", "/static/../main.go"} {
+		if rec := serve(s, target); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: got %d, want 404", target, rec.Code)
+		}
+	}
+}
+
+func TestServerSymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	staticDir := filepath.Join(dir, "static")
+	if err := os.Mkdir(staticDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(staticDir, "leak.txt")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	root, err := os.OpenRoot(staticDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+
+	if rec := serve(New(root.FS(), "/static/"), "/static/leak.txt"); rec.Code != http.StatusNotFound {
+		t.Errorf("symlink escaping the root: got %d, want 404", rec.Code)
+	}
+}
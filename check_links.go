@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"marianapparitions/linkcheck"
+	"marianapparitions/model"
+	"marianapparitions/repository"
+)
+
+// checkLinks verifies every Wikipedia and external source URL and writes a
+// report of dead links. It fails if any link is dead.
+func checkLinks(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("check-links", flag.ContinueOnError)
+	wikipediaEndpoint := fs.String("wikipedia-endpoint", model.WikipediaEndpoint, `Wikipedia article URL prefix, "{lang}" is replaced by the language`)
+	output := fs.String("o", "", "write the report to this file instead of stdout")
+	concurrency := fs.Int("concurrency", 4, "number of links checked in parallel")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := repository.GetAllEventsContext(ctx, db)
+	if err != nil {
+		return err
+	}
+	sources, err := repository.GetAllSourcesContext(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var links []linkcheck.Link
+	slugsByID := make(map[int]string)
+	for i := range events {
+		e := &events[i]
+		slugsByID[e.ID] = e.Slug()
+		if u := e.WikipediaURLAt(*wikipediaEndpoint, model.WikipediaLanguage); u != "" {
+			links = append(links, linkcheck.Link{EventSlug: e.Slug(), Kind: "wikipedia", URL: u})
+		}
+	}
+	for _, s := range sources {
+		if s.URL == "" {
+			continue
+		}
+		links = append(links, linkcheck.Link{EventSlug: slugsByID[s.EventID], Kind: "source", URL: s.URL})
+	}
+
+	slog.InfoContext(ctx, "Checking links", "count", len(links))
+	checker := linkcheck.NewChecker()
+	checker.Concurrency = *concurrency
+	results := checker.Check(ctx, links)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	dead, err := linkcheck.WriteReport(w, results)
+	if err != nil {
+		return err
+	}
+	if dead > 0 {
+		return fmt.Errorf("%d dead link(s)", dead)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package citation exports an event's external sources in formats that
+// reference managers understand (BibTeX and CSL-JSON).
+package citation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"marianapparitions/model"
+)
+
+// Key returns the citation key of the n-th (1-based) source of an event.
+func Key(eventSlug string, n int) string {
+	return fmt.Sprintf("%s-%d", eventSlug, n)
+}
+
+// WriteBibTeX writes one @online entry per source.
+func WriteBibTeX(w io.Writer, eventSlug string, sources []model.Source) error {
+	for i, s := range sources {
+		fields := [][2]string{
+			{"title", s.Title},
+			{"author", s.Author},
+			{"publisher", s.Publisher},
+			{"url", s.URL},
+			{"urldate", s.AccessedOn},
+		}
+		if _, err := fmt.Fprintf(w, "@online{%s,\n", Key(eventSlug, i+1)); err != nil {
+			return err
+		}
+		for _, f := range fields {
+			if f[1] == "" {
+				continue
+			}
+			value := f[1]
+			if f[0] != "url" {
+				value = escapeBibTeX(value)
+			}
+			if _, err := fmt.Fprintf(w, "  %s = {%s},\n", f[0], value); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "}\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var bibTeXReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+func escapeBibTeX(s string) string {
+	return bibTeXReplacer.Replace(s)
+}
+
+// cslItem is the subset of the CSL-JSON item schema we can fill in.
+// See https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html
+type cslItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title,omitempty"`
+	Author    []cslName `json:"author,omitempty"`
+	Publisher string    `json:"publisher,omitempty"`
+	URL       string    `json:"URL,omitempty"`
+	Accessed  *cslDate  `json:"accessed,omitempty"`
+}
+
+type cslName struct {
+	Literal string `json:"literal"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// WriteCSLJSON writes the sources as a CSL-JSON array.
+func WriteCSLJSON(w io.Writer, eventSlug string, sources []model.Source) error {
+	items := make([]cslItem, 0, len(sources))
+	for i, s := range sources {
+		item := cslItem{
+			ID:        Key(eventSlug, i+1),
+			Type:      "webpage",
+			Title:     s.Title,
+			Publisher: s.Publisher,
+			URL:       s.URL,
+		}
+		if s.Author != "" {
+			// Authors are stored as free text, so we can't split family/given names.
+			item.Author = []cslName{{Literal: s.Author}}
+		}
+		if d, err := time.Parse(time.DateOnly, s.AccessedOn); err == nil {
+			item.Accessed = &cslDate{DateParts: [][]int{{d.Year(), int(d.Month()), d.Day()}}}
+		}
+		items = append(items, item)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
@@ -0,0 +1,50 @@
+package citation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"marianapparitions/model"
+)
+
+func TestWriteBibTeX(t *testing.T) {
+	var buf bytes.Buffer
+	sources := []model.Source{
+		{URL: "https://example.org/fatima_1917", Title: "Fátima & the Sun", Author: "Jane Doe", AccessedOn: "2024-05-13"},
+	}
+	if err := WriteBibTeX(&buf, "our-lady-of-fatima", sources); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"@online{our-lady-of-fatima-1,",
+		"title = {Fátima \\& the Sun},",
+		"url = {https://example.org/fatima_1917},",
+		"urldate = {2024-05-13},",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "publisher") {
+		t.Errorf("empty fields should be omitted:\n%s", out)
+	}
+}
+
+func TestWriteCSLJSON(t *testing.T) {
+	var buf bytes.Buffer
+	sources := []model.Source{
+		{URL: "https://example.org", Title: "Lourdes", AccessedOn: "2024-02-11"},
+	}
+	if err := WriteCSLJSON(&buf, "our-lady-of-lourdes", sources); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"id": "our-lady-of-lourdes-1"`) {
+		t.Errorf("missing id in:\n%s", out)
+	}
+	if !strings.Contains(out, "2024,\n") {
+		t.Errorf("missing accessed date-parts in:\n%s", out)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// runCommand runs the command-line subcommand named by name.
+func runCommand(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "check-links":
+		return checkLinks(ctx, args)
+	case "lint":
+		return lintData(ctx, args)
+	case "dump":
+		return dumpDataset(ctx, args)
+	case "load":
+		return loadDataset(ctx, args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"marianapparitions/repository"
+	"marianapparitions/viewmodel"
+)
+
+// maxCompared bounds the number of columns of /compare
+const maxCompared = 6
+
+// handleCompare shows the events given as ?slug=a&slug=b side by side.
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	var slugs []string
+	for _, s := range r.URL.Query()["slug"] {
+		if s != "" && !slices.Contains(slugs, s) {
+			slugs = append(slugs, s)
+		}
+	}
+	if len(slugs) > maxCompared {
+		http.Error(w, fmt.Sprintf("At most %d apparitions can be compared", maxCompared), http.StatusBadRequest)
+		return
+	}
+
+	var events []*viewmodel.EventViewModel
+	var missing []string
+	for _, slug := range slugs {
+		e, err := repository.GetEventBySlugContext(r.Context(), db, slug)
+		if err == sql.ErrNoRows {
+			missing = append(missing, slug)
+			continue
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		events = append(events, viewmodel.NewEventVM(&e))
+	}
+
+	allEvents, err := indexEvents.Get(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	viewModel := viewmodel.NewCompareViewModel(events)
+	viewModel.Missing = missing
+	for i := range allEvents {
+		viewModel.Choices = append(viewModel.Choices, viewmodel.NewEventVM(&allEvents[i]))
+	}
+	coll := newCollator(requestLocale(r))
+	slices.SortFunc(viewModel.Choices, func(a, b *viewmodel.EventViewModel) int {
+		return coll.CompareString(a.Name, b.Name)
+	})
+
+	recordPageView(r.Context(), "/compare", "")
+	renderTemplate(w, r, "compare.html", viewModel)
+}
@@ -0,0 +1,352 @@
+// Package config loads the server configuration from, in increasing order
+// of precedence: built-in defaults, a TOML or YAML file, environment
+// variables and command-line flags.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	ListenAddr  string `yaml:"listen_addr" toml:"listen_addr"`
+	DBPath      string `yaml:"db_path" toml:"db_path"`
+	BaseURL     string `yaml:"base_url" toml:"base_url"` // Public URL of the site, e.g. https://apparitions.desrosiers.org
+	DefaultSort string `yaml:"default_sort" toml:"default_sort"`
+	// Where traces, metrics and logs go: "off", "stdout" or "otlp"
+	Telemetry string `yaml:"telemetry" toml:"telemetry"`
+	// Fraction of new traces to record, from 0 to 1. Spans with a parent
+	// (e.g. a traced caller) follow the parent's decision.
+	TraceSampleRatio float64 `yaml:"trace_sample_ratio" toml:"trace_sample_ratio"`
+	// Directory to read templates from instead of the embedded ones
+	TemplatesDir string `yaml:"templates_dir" toml:"templates_dir"`
+	// Directory to read schema.sql, templates/ and static/ from (development)
+	DevDir string `yaml:"dev_dir" toml:"dev_dir"`
+	// Minimum level logged: debug, info, warn or error
+	LogLevel string `yaml:"log_level" toml:"log_level"`
+	// "text" or "json"
+	LogFormat string `yaml:"log_format" toml:"log_format"`
+	// HTTP server timeouts. WriteTimeout also bounds streamed exports.
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	// How long in-flight requests get on shutdown, telemetry is then flushed
+	// for at most 5s more
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	Cache           CacheConfig   `yaml:"cache" toml:"cache"`
+	Admin           AdminConfig   `yaml:"admin" toml:"admin"`
+}
+
+// Telemetry modes
+const (
+	TelemetryOff    = "off"    // no-op providers
+	TelemetryStdout = "stdout" // pretty-printed to stdout, for local debugging
+	TelemetryOTLP   = "otlp"   // OTLP over HTTP, see OTEL_EXPORTER_OTLP_ENDPOINT
+)
+
+var TelemetryModes = []string{TelemetryOff, TelemetryStdout, TelemetryOTLP}
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+type CacheConfig struct {
+	// How long the events loaded for the index are reused, 0 disables the cache
+	EventsTTL time.Duration `yaml:"events_ttl" toml:"events_ttl"`
+	// max-age of fingerprinted static files
+	StaticMaxAge time.Duration `yaml:"static_max_age" toml:"static_max_age"`
+}
+
+// AdminConfig is the account of the editor (/admin and /api), which is
+// disabled while Password is empty.
+type AdminConfig struct {
+	User string `yaml:"user" toml:"user"`
+	// Prefer the ADMIN_PASSWORD environment variable to the config file
+	Password string `yaml:"password" toml:"password"`
+}
+
+// MinAdminPasswordLength is the shortest password the editor accepts.
+const MinAdminPasswordLength = 12
+
+// Default returns the configuration used when nothing overrides it.
+func Default() Config {
+	return Config{
+		ListenAddr:       ":8080",
+		DBPath:           "./data.sqlite3",
+		DefaultSort:      "year_desc",
+		Telemetry:        TelemetryOTLP,
+		TraceSampleRatio: 1,
+		LogLevel:         "info",
+		LogFormat:        LogFormatText,
+		ReadTimeout:      15 * time.Second,
+		WriteTimeout:     time.Minute,
+		ShutdownTimeout:  20 * time.Second,
+		Cache: CacheConfig{
+			EventsTTL:    30 * time.Second,
+			StaticMaxAge: 365 * 24 * time.Hour,
+		},
+		Admin: AdminConfig{User: "admin"},
+	}
+}
+
+// Load builds the configuration from the config file (-config flag or
+// CONFIG_FILE), the environment and the flags in args. It returns the
+// arguments left after the flags, i.e. the subcommand and its arguments.
+func Load(name string, args []string) (Config, []string, error) {
+	cfg := Default()
+
+	// Flags are parsed first to find -config, but applied last
+	var fromFlags Config
+	var configFile string
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&configFile, "config", os.Getenv("CONFIG_FILE"), "TOML or YAML configuration file")
+	fs.StringVar(&fromFlags.ListenAddr, "listen", "", "address to listen on, e.g. :8080 (env LISTEN_ADDR, or PORT)")
+	fs.StringVar(&fromFlags.DBPath, "db", "", "path of the SQLite database (env DB_PATH)")
+	fs.StringVar(&fromFlags.BaseURL, "base-url", "", "public URL of the site (env BASE_URL)")
+	fs.StringVar(&fromFlags.DefaultSort, "default-sort", "", "sort of the index when none is given (env DEFAULT_SORT)")
+	fs.StringVar(&fromFlags.Telemetry, "telemetry", "", "where to send traces, metrics and logs: off, stdout or otlp (env TELEMETRY)")
+	fs.Float64Var(&fromFlags.TraceSampleRatio, "trace-sample-ratio", 0, "fraction of traces to record, from 0 to 1 (env TRACE_SAMPLE_RATIO)")
+	fs.StringVar(&fromFlags.LogLevel, "log-level", "", "minimum level logged: debug, info, warn or error (env LOG_LEVEL)")
+	fs.StringVar(&fromFlags.LogFormat, "log-format", "", "log format: text or json (env LOG_FORMAT)")
+	fs.StringVar(&fromFlags.TemplatesDir, "templates-dir", "", "read templates from this directory instead of the embedded ones (env TEMPLATES_DIR)")
+	fs.StringVar(&fromFlags.DevDir, "dev-dir", "", "serve schema.sql, templates/ and static/ from this directory instead of the embedded copies (env DEV_DIR)")
+	fs.DurationVar(&fromFlags.ReadTimeout, "read-timeout", 0, "maximum duration to read a request (env READ_TIMEOUT)")
+	fs.DurationVar(&fromFlags.WriteTimeout, "write-timeout", 0, "maximum duration to write a response (env WRITE_TIMEOUT)")
+	fs.DurationVar(&fromFlags.ShutdownTimeout, "shutdown-timeout", 0, "how long to wait for in-flight requests on shutdown (env SHUTDOWN_TIMEOUT)")
+	fs.DurationVar(&fromFlags.Cache.EventsTTL, "cache-events-ttl", 0, "how long the index reuses loaded events, 0 disables (env CACHE_EVENTS_TTL)")
+	fs.DurationVar(&fromFlags.Cache.StaticMaxAge, "cache-static-max-age", 0, "max-age of fingerprinted static files (env CACHE_STATIC_MAX_AGE)")
+	// No flag for the password, command lines are visible to other users
+	fs.StringVar(&fromFlags.Admin.User, "admin-user", "", "user name of the editor, whose password is set with ADMIN_PASSWORD (env ADMIN_USER)")
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+
+	if configFile != "" {
+		if err := loadFile(&cfg, configFile); err != nil {
+			return cfg, nil, err
+		}
+	}
+
+	if err := applyEnv(&cfg, os.LookupEnv); err != nil {
+		return cfg, nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "listen":
+			cfg.ListenAddr = fromFlags.ListenAddr
+		case "db":
+			cfg.DBPath = fromFlags.DBPath
+		case "base-url":
+			cfg.BaseURL = fromFlags.BaseURL
+		case "default-sort":
+			cfg.DefaultSort = fromFlags.DefaultSort
+		case "telemetry":
+			cfg.Telemetry = fromFlags.Telemetry
+		case "trace-sample-ratio":
+			cfg.TraceSampleRatio = fromFlags.TraceSampleRatio
+		case "log-level":
+			cfg.LogLevel = fromFlags.LogLevel
+		case "log-format":
+			cfg.LogFormat = fromFlags.LogFormat
+		case "templates-dir":
+			cfg.TemplatesDir = fromFlags.TemplatesDir
+		case "dev-dir":
+			cfg.DevDir = fromFlags.DevDir
+		case "read-timeout":
+			cfg.ReadTimeout = fromFlags.ReadTimeout
+		case "write-timeout":
+			cfg.WriteTimeout = fromFlags.WriteTimeout
+		case "shutdown-timeout":
+			cfg.ShutdownTimeout = fromFlags.ShutdownTimeout
+		case "cache-events-ttl":
+			cfg.Cache.EventsTTL = fromFlags.Cache.EventsTTL
+		case "cache-static-max-age":
+			cfg.Cache.StaticMaxAge = fromFlags.Cache.StaticMaxAge
+		case "admin-user":
+			cfg.Admin.User = fromFlags.Admin.User
+		}
+	})
+
+	// Whichever source it comes from
+	mode, err := telemetryMode(cfg.Telemetry)
+	if err != nil {
+		return cfg, nil, err
+	}
+	cfg.Telemetry = mode
+
+	return cfg, fs.Args(), nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(path) {
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown key %q", path, undecoded[0].String())
+		}
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported config format, use .toml or .yaml", path)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	if v, ok := lookup("PORT"); ok && v != "" {
+		cfg.ListenAddr = ":" + v
+	}
+	stringVars := map[string]*string{
+		"LISTEN_ADDR":    &cfg.ListenAddr,
+		"DB_PATH":        &cfg.DBPath,
+		"BASE_URL":       &cfg.BaseURL,
+		"DEFAULT_SORT":   &cfg.DefaultSort,
+		"TEMPLATES_DIR":  &cfg.TemplatesDir,
+		"DEV_DIR":        &cfg.DevDir,
+		"LOG_LEVEL":      &cfg.LogLevel,
+		"LOG_FORMAT":     &cfg.LogFormat,
+		"ADMIN_USER":     &cfg.Admin.User,
+		"ADMIN_PASSWORD": &cfg.Admin.Password,
+	}
+	for name, dest := range stringVars {
+		if v, ok := lookup(name); ok && v != "" {
+			*dest = v
+		}
+	}
+
+	if v, ok := lookup("TELEMETRY"); ok && v != "" {
+		cfg.Telemetry = v
+	}
+	if v, ok := lookup("TRACE_SAMPLE_RATIO"); ok && v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("TRACE_SAMPLE_RATIO: %w", err)
+		}
+		cfg.TraceSampleRatio = ratio
+	}
+
+	durations := map[string]*time.Duration{
+		"READ_TIMEOUT":         &cfg.ReadTimeout,
+		"WRITE_TIMEOUT":        &cfg.WriteTimeout,
+		"SHUTDOWN_TIMEOUT":     &cfg.ShutdownTimeout,
+		"CACHE_EVENTS_TTL":     &cfg.Cache.EventsTTL,
+		"CACHE_STATIC_MAX_AGE": &cfg.Cache.StaticMaxAge,
+	}
+	for name, dest := range durations {
+		if v, ok := lookup(name); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*dest = d
+		}
+	}
+	return nil
+}
+
+// telemetryMode normalizes the telemetry setting: case and surrounding
+// spaces are ignored, "none" means off, and the boolean values TELEMETRY
+// used to take are mapped to modes, so existing deployments keep working.
+// It returns an error for other values.
+func telemetryMode(v string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(v))
+	switch mode {
+	case "on", "yes":
+		return TelemetryOTLP, nil
+	case "no", "none":
+		return TelemetryOff, nil
+	}
+	if on, err := strconv.ParseBool(mode); err == nil {
+		if on {
+			return TelemetryOTLP, nil
+		}
+		return TelemetryOff, nil
+	}
+	if !slices.Contains(TelemetryModes, mode) {
+		return v, fmt.Errorf("telemetry %q is not one of %v", v, TelemetryModes)
+	}
+	return mode, nil
+}
+
+// Validate checks the configuration. knownSorts are the valid values of DefaultSort.
+func (c Config) Validate(knownSorts []string) error {
+	var errs []error
+	if c.ListenAddr == "" {
+		errs = append(errs, errors.New("listen_addr is empty"))
+	}
+	if c.DBPath == "" {
+		errs = append(errs, errors.New("db_path is empty"))
+	}
+	if c.BaseURL != "" {
+		u, err := url.Parse(c.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("base_url %q is not an absolute http(s) URL", c.BaseURL))
+		}
+	}
+	if !slices.Contains(knownSorts, c.DefaultSort) {
+		errs = append(errs, fmt.Errorf("default_sort %q is not one of %v", c.DefaultSort, knownSorts))
+	}
+	for _, dir := range []struct{ key, path string }{{"templates_dir", c.TemplatesDir}, {"dev_dir", c.DevDir}} {
+		if dir.path == "" {
+			continue
+		}
+		if info, err := os.Stat(dir.path); err != nil || !info.IsDir() {
+			errs = append(errs, fmt.Errorf("%s %q is not a directory", dir.key, dir.path))
+		}
+	}
+	if c.Cache.EventsTTL < 0 || c.Cache.StaticMaxAge < 0 {
+		errs = append(errs, errors.New("cache durations can't be negative"))
+	}
+	if !slices.Contains(TelemetryModes, c.Telemetry) {
+		errs = append(errs, fmt.Errorf("telemetry %q is not one of %v", c.Telemetry, TelemetryModes))
+	}
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("trace_sample_ratio %v is not between 0 and 1", c.TraceSampleRatio))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("log_level %q is not one of debug, info, warn or error", c.LogLevel))
+	}
+	if c.LogFormat != LogFormatText && c.LogFormat != LogFormatJSON {
+		errs = append(errs, fmt.Errorf("log_format %q is not %s or %s", c.LogFormat, LogFormatText, LogFormatJSON))
+	}
+	if c.ReadTimeout <= 0 || c.WriteTimeout <= 0 || c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("read_timeout, write_timeout and shutdown_timeout must be positive"))
+	}
+	if c.Admin.Password != "" {
+		if c.Admin.User == "" {
+			errs = append(errs, errors.New("admin.user is empty"))
+		}
+		if len(c.Admin.Password) < MinAdminPasswordLength {
+			errs = append(errs, fmt.Errorf("admin.password is shorter than %d characters", MinAdminPasswordLength))
+		}
+	}
+	return errors.Join(errs...)
+}
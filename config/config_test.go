@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte(`
+listen_addr = ":9000"
+db_path = "/from/file.sqlite3"
+default_sort = "name_asc"
+
+[cache]
+events_ttl = "1m"
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_PATH", "/from/env.sqlite3")
+	t.Setenv("DEFAULT_SORT", "name_desc")
+
+	cfg, rest, err := Load("test", []string{"-config", path, "-default-sort", "year_asc", "lint", "-maps", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.ListenAddr != ":9000" {
+		t.Errorf("ListenAddr = %q, want the file's value", cfg.ListenAddr)
+	}
+	if cfg.DBPath != "/from/env.sqlite3" {
+		t.Errorf("DBPath = %q, want the environment to override the file", cfg.DBPath)
+	}
+	if cfg.DefaultSort != "year_asc" {
+		t.Errorf("DefaultSort = %q, want the flag to override the environment", cfg.DefaultSort)
+	}
+	if cfg.Cache.EventsTTL != time.Minute {
+		t.Errorf("EventsTTL = %v, want 1m", cfg.Cache.EventsTTL)
+	}
+	if cfg.Cache.StaticMaxAge != Default().Cache.StaticMaxAge {
+		t.Errorf("StaticMaxAge = %v, want the default", cfg.Cache.StaticMaxAge)
+	}
+	if len(rest) != 3 || rest[0] != "lint" {
+		t.Errorf("remaining args = %q, want the subcommand and its flags", rest)
+	}
+}
+
+func TestTelemetryMode(t *testing.T) {
+	for in, want := range map[string]string{
+		"true":   TelemetryOTLP,
+		"on":     TelemetryOTLP,
+		"0":      TelemetryOff,
+		"off":    TelemetryOff,
+		"none":   TelemetryOff,
+		"stdout": TelemetryStdout,
+		"otlp":   TelemetryOTLP,
+		" OTLP":  TelemetryOTLP,
+	} {
+		if got, err := telemetryMode(in); err != nil || got != want {
+			t.Errorf("telemetryMode(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := telemetryMode("otel"); err == nil {
+		t.Error("telemetryMode accepted an unknown mode")
+	}
+
+	cfg := Default()
+	cfg.Telemetry = "bogus"
+	cfg.TraceSampleRatio = 2
+	if err := cfg.Validate([]string{cfg.DefaultSort}); err == nil {
+		t.Error("Validate accepted an unknown telemetry mode and a sample ratio above 1")
+	}
+}
+
+func TestLoadTelemetryFromFile(t *testing.T) {
+	t.Setenv("TELEMETRY", "") // Ignored, so the file's value is used
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("telemetry: \"False\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := Load("test", []string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Telemetry != TelemetryOff {
+		t.Errorf("Telemetry = %q, want the file's value normalized to %q", cfg.Telemetry, TelemetryOff)
+	}
+
+	if err := os.WriteFile(path, []byte("telemetry: stdot\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Load("test", []string{"-config", path}); err == nil {
+		t.Error("Load accepted an unknown telemetry mode from the file")
+	}
+}
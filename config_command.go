@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configCommand implements `config show`, which prints the effective
+// configuration as YAML (it can be used as a config file).
+func configCommand(args []string) error {
+	if len(args) != 1 || args[0] != "show" {
+		return fmt.Errorf("usage: config show")
+	}
+
+	shown := cfg
+	if shown.Admin.Password != "" {
+		shown.Admin.Password = "(hidden)"
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(shown); err != nil {
+		return err
+	}
+	return enc.Close()
+}
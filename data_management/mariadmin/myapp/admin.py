@@ -1,7 +1,7 @@
 from django.contrib import admin
 from django import forms
 from adminsortable2.admin import SortableAdminBase, SortableInlineAdminMixin
-from .models import Events, EventBlock, MarysRequests, ExternalSources
+from .models import Events, EventBlock, MarysRequests, ExternalSources, Media, Shrines
 
 
 class EventBlockInlineForm(forms.ModelForm):
@@ -21,6 +21,19 @@ class EventBlockInline(SortableInlineAdminMixin, admin.TabularInline):
     ordering = ['ordering']
 
 
+class MediaInline(SortableInlineAdminMixin, admin.TabularInline):
+    model = Media
+    extra = 1
+    fields = ['kind', 'source', 'caption', 'credit', 'license', 'ordering']
+    ordering = ['ordering']
+
+
+class ShrinesInline(admin.StackedInline):
+    model = Shrines
+    extra = 0
+    fields = ['name', 'website', ('latitude', 'longitude'), 'founded_year', 'built_at_marys_request', 'request']
+
+
 class EventsForm(forms.ModelForm):
     class Meta:
         model = Events
@@ -43,7 +56,7 @@ class EventsAdmin(SortableAdminBase, admin.ModelAdmin):
     list_display = ['name', 'category', 'years', 'country', 'block_count']
     search_fields = ['name', 'description']
     list_filter = ['category', 'years']
-    inlines = [EventBlockInline]
+    inlines = [EventBlockInline, MediaInline, ShrinesInline]
 
     @admin.display(description='Blocks')
     def block_count(self, obj):
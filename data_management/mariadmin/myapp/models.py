@@ -14,7 +14,7 @@ class Events(models.Model):
     wikipedia_section_title = models.TextField(blank=True, null=True)
     image_filename = models.TextField(blank=True, null=True)
     years = models.TextField(blank=True, null=True)
-    slug = models.TextField(blank=True, null=True)
+    slug = models.TextField(blank=True, null=True, unique=True)
     country = models.TextField(blank=True, null=True)
 
     class Meta:
@@ -37,11 +37,50 @@ class ExternalSources(models.Model):
     id = models.AutoField(primary_key=True)
     event = models.ForeignKey(Events, on_delete=models.CASCADE, db_column='event_id')
     source_url = models.TextField(blank=True, null=True)
+    title = models.TextField(blank=True, null=True)
+    author = models.TextField(blank=True, null=True)
+    publisher = models.TextField(blank=True, null=True)
+    accessed_on = models.TextField(blank=True, null=True, help_text='YYYY-MM-DD')
 
     class Meta:
         managed = False
         db_table = 'external_sources'
 
+class Media(models.Model):
+    KIND_CHOICES = [('image', 'Image'), ('video', 'Video link'), ('audio', 'Audio')]
+
+    id = models.AutoField(primary_key=True)
+    event = models.ForeignKey(Events, on_delete=models.CASCADE, related_name='media', db_column='event_id')
+    kind = models.TextField(choices=KIND_CHOICES, default='image')
+    source = models.TextField(help_text='File name under static/images/, or an absolute URL')
+    caption = models.TextField(blank=True, null=True)
+    credit = models.TextField(blank=True, null=True)
+    license = models.TextField(blank=True, null=True)
+    ordering = models.IntegerField(default=0)
+
+    class Meta:
+        managed = False
+        db_table = 'media'
+        ordering = ['ordering', 'id']
+
+class Shrines(models.Model):
+    id = models.AutoField(primary_key=True)
+    event = models.ForeignKey(Events, on_delete=models.CASCADE, related_name='shrines', db_column='event_id')
+    name = models.TextField()
+    website = models.TextField(blank=True, null=True)
+    latitude = models.FloatField(blank=True, null=True)
+    longitude = models.FloatField(blank=True, null=True)
+    founded_year = models.IntegerField(blank=True, null=True)
+    built_at_marys_request = models.BooleanField(default=False)
+    request = models.ForeignKey(MarysRequests, on_delete=models.SET_NULL, blank=True, null=True, db_column='request_id')
+
+    class Meta:
+        managed = False
+        db_table = 'shrines'
+
+    def __str__(self):
+        return self.name
+
 class EventBlock(models.Model):
     id = models.AutoField(primary_key=True)
     event = models.ForeignKey(Events, on_delete=models.CASCADE, related_name='blocks', db_column='event_id')
@@ -0,0 +1,129 @@
+// Package dataset converts the apparition database to and from plain
+// JSON/YAML files (one per event), so the data can be versioned and
+// reviewed in git.
+package dataset
+
+import (
+	"marianapparitions/model"
+)
+
+// Event is the on-disk representation of an event and everything attached
+// to it. Database IDs are left out: events are keyed by slug.
+type Event struct {
+	Slug                  string    `json:"slug" yaml:"slug"`
+	Name                  string    `json:"name" yaml:"name"`
+	Category              string    `json:"category" yaml:"category"`
+	Years                 string    `json:"years" yaml:"years"`
+	Country               string    `json:"country,omitempty" yaml:"country,omitempty"`
+	Description           string    `json:"description,omitempty" yaml:"description,omitempty"`
+	WikipediaSectionTitle string    `json:"wikipedia_section_title,omitempty" yaml:"wikipedia_section_title,omitempty"`
+	ImageFilename         string    `json:"image_filename,omitempty" yaml:"image_filename,omitempty"`
+	Blocks                []Block   `json:"blocks,omitempty" yaml:"blocks,omitempty"`
+	Requests              []Request `json:"requests,omitempty" yaml:"requests,omitempty"`
+	Sources               []Source  `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Media                 []Media   `json:"media,omitempty" yaml:"media,omitempty"`
+	Shrines               []Shrine  `json:"shrines,omitempty" yaml:"shrines,omitempty"`
+}
+
+type Block struct {
+	Language          string `json:"language" yaml:"language"`
+	Title             string `json:"title" yaml:"title"`
+	Content           string `json:"content" yaml:"content"`
+	Ordering          int    `json:"ordering" yaml:"ordering"`
+	ChurchAuthority   string `json:"church_authority,omitempty" yaml:"church_authority,omitempty"`
+	AuthorityPosition string `json:"authority_position,omitempty" yaml:"authority_position,omitempty"`
+}
+
+type Request struct {
+	Request string `json:"request" yaml:"request"`
+}
+
+type Source struct {
+	URL        string `json:"url" yaml:"url"`
+	Title      string `json:"title,omitempty" yaml:"title,omitempty"`
+	Author     string `json:"author,omitempty" yaml:"author,omitempty"`
+	Publisher  string `json:"publisher,omitempty" yaml:"publisher,omitempty"`
+	AccessedOn string `json:"accessed_on,omitempty" yaml:"accessed_on,omitempty"`
+}
+
+type Media struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	Source   string `json:"source" yaml:"source"`
+	Caption  string `json:"caption,omitempty" yaml:"caption,omitempty"`
+	Credit   string `json:"credit,omitempty" yaml:"credit,omitempty"`
+	License  string `json:"license,omitempty" yaml:"license,omitempty"`
+	Ordering int    `json:"ordering" yaml:"ordering"`
+}
+
+type Shrine struct {
+	Name                string   `json:"name" yaml:"name"`
+	Website             string   `json:"website,omitempty" yaml:"website,omitempty"`
+	Latitude            *float64 `json:"latitude,omitempty" yaml:"latitude,omitempty"`
+	Longitude           *float64 `json:"longitude,omitempty" yaml:"longitude,omitempty"`
+	FoundedYear         int      `json:"founded_year,omitempty" yaml:"founded_year,omitempty"`
+	BuiltAtMarysRequest bool     `json:"built_at_marys_request,omitempty" yaml:"built_at_marys_request,omitempty"`
+	// Text of the request that asked for the shrine; it must match one of
+	// the event's requests.
+	Request string `json:"request,omitempty" yaml:"request,omitempty"`
+}
+
+// FromModel converts a fully loaded model.Event.
+func FromModel(e *model.Event) Event {
+	out := Event{
+		Slug:                  e.Slug(),
+		Name:                  e.Name,
+		Category:              e.Category,
+		Years:                 e.Years,
+		Country:               e.Country,
+		Description:           e.Description,
+		WikipediaSectionTitle: e.WikipediaSectionTitle,
+		ImageFilename:         e.ImageFilename,
+	}
+	for _, b := range e.Blocks {
+		out.Blocks = append(out.Blocks, Block{
+			Language:          b.Language,
+			Title:             b.Title,
+			Content:           b.Content,
+			Ordering:          b.Ordering,
+			ChurchAuthority:   b.ChurchAuthority,
+			AuthorityPosition: b.AuthorityPosition,
+		})
+	}
+	for _, r := range e.Requests {
+		out.Requests = append(out.Requests, Request{Request: r.Request})
+	}
+	for _, s := range e.Sources {
+		out.Sources = append(out.Sources, Source{
+			URL:        s.URL,
+			Title:      s.Title,
+			Author:     s.Author,
+			Publisher:  s.Publisher,
+			AccessedOn: s.AccessedOn,
+		})
+	}
+	for _, m := range e.Media {
+		out.Media = append(out.Media, Media{
+			Kind:     m.Kind,
+			Source:   m.Source,
+			Caption:  m.Caption,
+			Credit:   m.Credit,
+			License:  m.License,
+			Ordering: m.Ordering,
+		})
+	}
+	for _, s := range e.Shrines {
+		shrine := Shrine{
+			Name:                s.Name,
+			Website:             s.Website,
+			FoundedYear:         s.FoundedYear,
+			BuiltAtMarysRequest: s.BuiltAtMarysRequest,
+			Request:             s.Request,
+		}
+		if s.HasCoordinates {
+			lat, lng := s.Latitude, s.Longitude
+			shrine.Latitude, shrine.Longitude = &lat, &lng
+		}
+		out.Shrines = append(out.Shrines, shrine)
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats supported by Write, mapped to their file extension.
+var Formats = map[string]string{
+	"json": ".json",
+	"yaml": ".yaml",
+}
+
+// Write writes one file per event in dir, named after the event's slug.
+// Files of the same format left over from deleted events are removed, so
+// the directory always mirrors the database.
+func Write(dir, format string, events []Event) error {
+	ext, ok := Formats[format]
+	if !ok {
+		return fmt.Errorf("unsupported format %q", format)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	written := make(map[string]bool)
+	for _, e := range events {
+		if e.Slug == "" || strings.ContainsAny(e.Slug, `/\`) {
+			return fmt.Errorf("event %q has an invalid slug %q", e.Name, e.Slug)
+		}
+		name := e.Slug + ext
+		if written[name] {
+			return fmt.Errorf("duplicate slug %q", e.Slug)
+		}
+		written[name] = true
+
+		data, err := encode(format, e)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Slug, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ext && !written[entry.Name()] {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func encode(format string, e Event) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	case "yaml":
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Read reads every .json, .yaml and .yml file in dir, sorted by slug.
+func Read(dir string) ([]Event, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		var e Event
+		switch filepath.Ext(entry.Name()) {
+		case ".json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.DisallowUnknownFields()
+			err = dec.Decode(&e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		case ".yaml", ".yml":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			dec := yaml.NewDecoder(bytes.NewReader(data))
+			dec.KnownFields(true)
+			if err := dec.Decode(&e); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		default:
+			continue
+		}
+		events = append(events, e)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Slug < events[j].Slug })
+	return events, nil
+}
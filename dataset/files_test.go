@@ -0,0 +1,49 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	lat, lng := 37.6172, -8.6506
+	events := []Event{
+		{
+			Slug: "our-lady-of-fatima", Name: "Our Lady of Fátima", Category: "Apparition", Years: "1917",
+			Blocks:   []Block{{Language: "en", Title: "Excerpt", Content: "Line one\nLine two", Ordering: 1}},
+			Requests: []Request{{Request: "Build a chapel"}},
+			Shrines:  []Shrine{{Name: "Sanctuary of Fátima", Latitude: &lat, Longitude: &lng, Request: "Build a chapel"}},
+		},
+		{Slug: "our-lady-of-akita", Name: "Our Lady of Akita", Category: "Apparition", Years: "1973"},
+	}
+
+	for format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			// A file left over from a deleted event must be removed
+			stale := filepath.Join(dir, "deleted-event"+Formats[format])
+			if err := os.WriteFile(stale, []byte("{}"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Write(dir, format, events); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(stale); !os.IsNotExist(err) {
+				t.Errorf("stale file was not removed")
+			}
+
+			got, err := Read(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Read sorts by slug
+			want := []Event{events[1], events[0]}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
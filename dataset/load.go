@@ -0,0 +1,200 @@
+package dataset
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// LoadStats summarizes what Load did.
+type LoadStats struct {
+	Created int
+	Updated int
+	Pruned  int
+}
+
+// Load imports events into db in a single transaction. Events are matched
+// by slug: existing ones are updated and their blocks, requests, sources,
+// media and shrines replaced, so loading the same files twice yields the
+// same database content. With prune, events whose slug isn't in the
+// dataset are deleted.
+func Load(ctx context.Context, db *sql.DB, events []Event, prune bool) (LoadStats, error) {
+	var stats LoadStats
+
+	seen := make(map[string]bool)
+	for _, e := range events {
+		if e.Slug == "" {
+			return stats, fmt.Errorf("event %q has no slug", e.Name)
+		}
+		if seen[e.Slug] {
+			return stats, fmt.Errorf("duplicate slug %q", e.Slug)
+		}
+		seen[e.Slug] = true
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	for _, e := range events {
+		created, err := loadEvent(ctx, tx, e)
+		if err != nil {
+			return stats, fmt.Errorf("%s: %w", e.Slug, err)
+		}
+		if created {
+			stats.Created++
+		} else {
+			stats.Updated++
+		}
+	}
+
+	if prune {
+		rows, err := tx.QueryContext(ctx, "SELECT id, COALESCE(slug, '') FROM events")
+		if err != nil {
+			return stats, err
+		}
+		var stale []int
+		for rows.Next() {
+			var id int
+			var slug string
+			if err := rows.Scan(&id, &slug); err != nil {
+				rows.Close()
+				return stats, err
+			}
+			if !seen[slug] {
+				stale = append(stale, id)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return stats, err
+		}
+
+		for _, id := range stale {
+			if err := deleteChildren(ctx, tx, id); err != nil {
+				return stats, err
+			}
+			if _, err := tx.ExecContext(ctx, "DELETE FROM events WHERE id = ?", id); err != nil {
+				return stats, err
+			}
+			stats.Pruned++
+		}
+	}
+
+	return stats, tx.Commit()
+}
+
+func loadEvent(ctx context.Context, tx *sql.Tx, e Event) (created bool, err error) {
+	var id int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM events WHERE slug = ?", e.Slug).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO events (slug, name, category, years, country, description, wikipedia_section_title, image_filename) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.Slug, e.Name, e.Category, e.Years, e.Country, e.Description, e.WikipediaSectionTitle, e.ImageFilename)
+		if err != nil {
+			return false, err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return false, err
+		}
+		created = true
+	case err != nil:
+		return false, err
+	default:
+		_, err = tx.ExecContext(ctx,
+			`UPDATE events SET name = ?, category = ?, years = ?, country = ?, description = ?, wikipedia_section_title = ?, image_filename = ? WHERE id = ?`,
+			e.Name, e.Category, e.Years, e.Country, e.Description, e.WikipediaSectionTitle, e.ImageFilename, id)
+		if err != nil {
+			return false, err
+		}
+		if err := deleteChildren(ctx, tx, int(id)); err != nil {
+			return false, err
+		}
+	}
+
+	for _, b := range e.Blocks {
+		language := b.Language
+		if language == "" {
+			language = "en"
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO event_blocks (event_id, language, title, content, ordering, church_authority, authority_position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+			id, language, b.Title, b.Content, b.Ordering, nullIfEmpty(b.ChurchAuthority), nullIfEmpty(b.AuthorityPosition))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	requestIDs := make(map[string]int64)
+	for _, r := range e.Requests {
+		res, err := tx.ExecContext(ctx, `INSERT INTO marys_requests (event_id, request) VALUES (?, ?)`, id, r.Request)
+		if err != nil {
+			return false, err
+		}
+		if requestIDs[r.Request], err = res.LastInsertId(); err != nil {
+			return false, err
+		}
+	}
+
+	for _, s := range e.Sources {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO external_sources (event_id, source_url, title, author, publisher, accessed_on) VALUES (?, ?, ?, ?, ?, ?)`,
+			id, s.URL, nullIfEmpty(s.Title), nullIfEmpty(s.Author), nullIfEmpty(s.Publisher), nullIfEmpty(s.AccessedOn))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, m := range e.Media {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO media (event_id, kind, source, caption, credit, license, ordering) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, m.Kind, m.Source, nullIfEmpty(m.Caption), nullIfEmpty(m.Credit), nullIfEmpty(m.License), m.Ordering)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, s := range e.Shrines {
+		var requestID any
+		if s.Request != "" {
+			rid, ok := requestIDs[s.Request]
+			if !ok {
+				return false, fmt.Errorf("shrine %q refers to an unknown request %q", s.Name, s.Request)
+			}
+			requestID = rid
+		}
+		var foundedYear any
+		if s.FoundedYear != 0 {
+			foundedYear = s.FoundedYear
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO shrines (event_id, name, website, latitude, longitude, founded_year, built_at_marys_request, request_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, s.Name, nullIfEmpty(s.Website), s.Latitude, s.Longitude, foundedYear, s.BuiltAtMarysRequest, requestID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return created, nil
+}
+
+func deleteChildren(ctx context.Context, tx *sql.Tx, eventID int) error {
+	// Shrines first, they may reference marys_requests
+	for _, table := range []string{"shrines", "event_blocks", "marys_requests", "external_sources", "media"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE event_id = ?", eventID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
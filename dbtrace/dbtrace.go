@@ -0,0 +1,349 @@
+// Package dbtrace instruments a database/sql driver with OpenTelemetry.
+// Every query, exec and transaction of the wrapped connections gets a
+// client span with its sanitized statement and row count, and its duration
+// is recorded in the db.client.operation.duration histogram.
+package dbtrace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("marianapparitions/dbtrace")
+
+var meter = otel.Meter("marianapparitions/dbtrace")
+
+var operationDuration, _ = meter.Float64Histogram("db.client.operation.duration",
+	metric.WithDescription("Duration of database client operations"),
+	metric.WithUnit("s"),
+	metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10))
+
+// OpenDB is like sql.Open, with the connections of driver d instrumented.
+// system is the db.system attribute of the spans, e.g. "sqlite".
+func OpenDB(d driver.Driver, dsn, system string) *sql.DB {
+	return sql.OpenDB(&connector{driver: d, dsn: dsn, system: system})
+}
+
+type connector struct {
+	driver driver.Driver
+	dsn    string
+	system string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	var cn driver.Conn
+	var err error
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		var inner driver.Connector
+		if inner, err = dc.OpenConnector(c.dsn); err == nil {
+			cn, err = inner.Connect(ctx)
+		}
+	} else {
+		cn, err = c.driver.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, system: c.system}, nil
+}
+
+func (c *connector) Driver() driver.Driver { return c.driver }
+
+// operation is an instrumented call, from its start to end.
+type operation struct {
+	ctx    context.Context
+	span   trace.Span
+	start  time.Time
+	system string
+	name   string
+}
+
+func startOperation(ctx context.Context, system, query string) (context.Context, *operation) {
+	statement := Sanitize(query)
+	name := operationName(statement)
+	spanName := name
+	if spanName == "" {
+		spanName = system
+	}
+	ctx, span := tracer.Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", system),
+			attribute.String("db.operation.name", name),
+			attribute.String("db.query.text", statement),
+		))
+	return ctx, &operation{ctx: ctx, span: span, start: time.Now(), system: system, name: name}
+}
+
+func (op *operation) end(err error, attrs ...attribute.KeyValue) {
+	metricAttrs := []attribute.KeyValue{
+		attribute.String("db.system", op.system),
+		attribute.String("db.operation.name", op.name),
+	}
+	if err != nil {
+		op.span.RecordError(err)
+		op.span.SetStatus(codes.Error, err.Error())
+		metricAttrs = append(metricAttrs, attribute.String("error.type", reflect.TypeOf(err).String()))
+	}
+	op.span.SetAttributes(attrs...)
+	op.span.End()
+	operationDuration.Record(op.ctx, time.Since(op.start).Seconds(), metric.WithAttributes(metricAttrs...))
+}
+
+func (op *operation) endExec(res driver.Result, err error) {
+	if err != nil {
+		op.end(err)
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		op.end(nil, attribute.Int64("db.response.affected_rows", n))
+		return
+	}
+	op.end(nil)
+}
+
+type conn struct {
+	driver.Conn
+	system string
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, query: query, system: c.system}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	_, op := startOperation(ctx, c.system, "BEGIN")
+	var t driver.Tx
+	var err error
+	if bt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		t, err = bt.BeginTx(ctx, opts)
+	} else {
+		t, err = c.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+	}
+	op.end(err)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t, ctx: ctx, system: c.system}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql then prepares a statement, which is instrumented
+		return nil, driver.ErrSkip
+	}
+	ctx, op := startOperation(ctx, c.system, query)
+	res, err := ec.ExecContext(ctx, query, args)
+	op.endExec(res, err)
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, op := startOperation(ctx, c.system, query)
+	r, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		op.end(err)
+		return nil, err
+	}
+	return &rows{Rows: r, op: op}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type tx struct {
+	driver.Tx
+	ctx    context.Context // of BeginTx, the driver API doesn't pass one to Commit
+	system string
+}
+
+func (t *tx) Commit() error {
+	_, op := startOperation(t.ctx, t.system, "COMMIT")
+	err := t.Tx.Commit()
+	op.end(err)
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, op := startOperation(t.ctx, t.system, "ROLLBACK")
+	err := t.Tx.Rollback()
+	op.end(err)
+	return err
+}
+
+type stmt struct {
+	driver.Stmt
+	query  string
+	system string
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, op := startOperation(ctx, s.system, s.query)
+	var res driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else if values, verr := plainValues(args); verr != nil {
+		err = verr
+	} else {
+		res, err = s.Stmt.Exec(values) //nolint:staticcheck // fallback for drivers without ExecContext
+	}
+	op.endExec(res, err)
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, op := startOperation(ctx, s.system, s.query)
+	var r driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		r, err = qc.QueryContext(ctx, args)
+	} else if values, verr := plainValues(args); verr != nil {
+		err = verr
+	} else {
+		r, err = s.Stmt.Query(values) //nolint:staticcheck // fallback for drivers without QueryContext
+	}
+	if err != nil {
+		op.end(err)
+		return nil, err
+	}
+	return &rows{Rows: r, op: op}, nil
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// rows ends the query's operation when closed, with the number of rows read.
+type rows struct {
+	driver.Rows
+	op    *operation
+	count int64
+	err   error
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.count++
+	} else if err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *rows) Close() error {
+	err := r.Rows.Close()
+	r.op.end(errors.Join(r.err, err), attribute.Int64("db.response.returned_rows", r.count))
+	return err
+}
+
+func (r *rows) ColumnTypeScanType(i int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(i)
+	}
+	return reflect.TypeFor[any]()
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(i)
+	}
+	return ""
+}
+
+func (r *rows) ColumnTypeNullable(i int) (nullable, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(i)
+	}
+	return false, false
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+func plainValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("dbtrace: driver does not support named parameters")
+		}
+		values[i] = a.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,86 @@
+package dbtrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSanitize(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"SELECT id FROM events WHERE slug = ?", "SELECT id FROM events WHERE slug = ?"},
+		{"SELECT * FROM events WHERE name = 'Fatima' AND id > 12", "SELECT * FROM events WHERE name = ? AND id > ?"},
+		{"SELECT 'it''s', 3.14", "SELECT ?, ?"},
+		{"ALTER TABLE event_blocks ADD COLUMN \"language\" VARCHAR(10)", "ALTER TABLE event_blocks ADD COLUMN \"language\" VARCHAR(?)"},
+		{"SELECT v2 FROM t -- the 'v2' column\n  WHERE x = 1 /* 2 */", "SELECT v2 FROM t WHERE x = ?"},
+	} {
+		if got := Sanitize(tc.in); got != tc.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+	otel.SetTracerProvider(tp)
+
+	db := OpenDB(&sqlite3.SQLiteDriver{}, ":memory:", "sqlite")
+	defer db.Close()
+	db.SetMaxOpenConns(1) // a single in-memory database
+
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE t (name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO t VALUES ('a'), ('b'), ('c')"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT name FROM t WHERE name <> ?", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+
+	spans := exporter.GetSpans()
+	var names []string
+	attrs := map[string]string{}
+	for _, s := range spans {
+		names = append(names, s.Name)
+		for _, a := range s.Attributes {
+			attrs[s.Name+" "+string(a.Key)] = a.Value.Emit()
+		}
+	}
+	want := []string{"CREATE", "BEGIN", "INSERT", "COMMIT", "SELECT"}
+	if len(names) != len(want) {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("spans = %v, want %v", names, want)
+		}
+	}
+	if got := attrs["INSERT db.query.text"]; got != "INSERT INTO t VALUES (?), (?), (?)" {
+		t.Errorf("INSERT db.query.text = %q, want the literals replaced", got)
+	}
+	if got := attrs["INSERT db.response.affected_rows"]; got != "3" {
+		t.Errorf("INSERT affected rows = %s, want 3", got)
+	}
+	if got := attrs["SELECT db.response.returned_rows"]; got != "2" {
+		t.Errorf("SELECT returned rows = %s, want 2", got)
+	}
+}
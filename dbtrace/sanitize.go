@@ -0,0 +1,112 @@
+package dbtrace
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxStatementLen bounds db.query.text, schema.sql is executed as a whole.
+const maxStatementLen = 2048
+
+// Sanitize replaces the string and number literals of a SQL statement with
+// ?, so values inlined in it (rather than passed as arguments) don't end up
+// in traces. It also drops comments and collapses whitespace.
+func Sanitize(query string) string {
+	var b strings.Builder
+	rs := []rune(query)
+	space := false
+	emit := func(r ...rune) {
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		for _, c := range r {
+			b.WriteRune(c)
+		}
+	}
+	prevIdent := func() bool {
+		s := b.String()
+		if s == "" || space {
+			return false
+		}
+		last := rune(s[len(s)-1])
+		return last == '_' || unicode.IsLetter(last) || unicode.IsDigit(last)
+	}
+
+	for i := 0; i < len(rs); i++ {
+		switch c := rs[i]; {
+		case unicode.IsSpace(c):
+			space = true
+		case c == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			space = true
+		case c == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			i += 2
+			for i+1 < len(rs) && !(rs[i] == '*' && rs[i+1] == '/') {
+				i++
+			}
+			i++
+			space = true
+		case c == '\'':
+			// '' is an escaped quote inside a string
+			for i++; i < len(rs); i++ {
+				if rs[i] == '\'' {
+					if i+1 < len(rs) && rs[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			emit('?')
+		case c == '"' || c == '`' || c == '[':
+			// Quoted identifiers are kept
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := i + 1
+			for j < len(rs) && rs[j] != end {
+				j++
+			}
+			if j >= len(rs) {
+				j = len(rs) - 1
+			}
+			emit(rs[i : j+1]...)
+			i = j
+		case unicode.IsDigit(c) && !prevIdent():
+			for i+1 < len(rs) && (unicode.IsDigit(rs[i+1]) || rs[i+1] == '.') {
+				i++
+			}
+			emit('?')
+		default:
+			emit(c)
+		}
+	}
+
+	s := b.String()
+	if len(s) > maxStatementLen {
+		cut := maxStatementLen
+		for !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut] + "..."
+	}
+	return s
+}
+
+// operationName returns the first keyword of a sanitized statement, e.g.
+// SELECT, used as the span name and db.operation.name.
+func operationName(statement string) string {
+	name, _, _ := strings.Cut(statement, " ")
+	name = strings.TrimRight(name, ";(")
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			return ""
+		}
+	}
+	return strings.ToUpper(name)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"marianapparitions/dataset"
+	"marianapparitions/repository"
+)
+
+// dumpDataset exports every event, with its blocks, requests, sources,
+// media and shrines, to one file per event.
+func dumpDataset(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	dir := fs.String("dir", "dataset", "directory to write the event files to")
+	format := fs.String("format", "yaml", "file format: json or yaml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	allEvents, err := repository.GetAllEventsContext(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	events := make([]dataset.Event, 0, len(allEvents))
+	for i := range allEvents {
+		// GetAllEvents only loads what the index needs. A child that fails to
+		// load must fail the dump, or `load --prune` would delete it.
+		e, err := repository.GetEventByIDContext(ctx, db, allEvents[i].ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", allEvents[i].Slug(), err)
+		}
+		events = append(events, dataset.FromModel(&e))
+	}
+
+	if err := dataset.Write(*dir, *format, events); err != nil {
+		return err
+	}
+	slog.Info("Dumped dataset", "events", len(events), "dir", *dir)
+	return nil
+}
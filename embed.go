@@ -0,0 +1,62 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"log/slog"
+	"os"
+)
+
+// embeddedFiles makes the binary self-contained: it doesn't need to run
+// from the source directory.
+//
+//go:embed schema.sql templates static
+var embeddedFiles embed.FS
+
+// appFS holds schema.sql, templates/ and static/, and templatesFS the
+// templates. They are the embedded copies unless overridden by the dev_dir
+// and templates_dir settings.
+var (
+	appFS       fs.FS = embeddedFiles
+	templatesFS fs.FS
+)
+
+// setupFiles points appFS and templatesFS at the configured directories.
+// The returned function releases them.
+func setupFiles() (func(), error) {
+	var roots []*os.Root
+	closeRoots := func() {
+		for _, r := range roots {
+			r.Close()
+		}
+	}
+
+	// os.Root keeps symlinks from escaping the directories
+	if cfg.DevDir != "" {
+		root, err := os.OpenRoot(cfg.DevDir)
+		if err != nil {
+			return closeRoots, err
+		}
+		roots = append(roots, root)
+		appFS = root.FS()
+		slog.Info("Serving files from disk", "dir", cfg.DevDir)
+	}
+
+	if cfg.TemplatesDir != "" {
+		root, err := os.OpenRoot(cfg.TemplatesDir)
+		if err != nil {
+			return closeRoots, err
+		}
+		roots = append(roots, root)
+		templatesFS = root.FS()
+		slog.Info("Serving templates from disk", "dir", cfg.TemplatesDir)
+	} else {
+		sub, err := fs.Sub(appFS, "templates")
+		if err != nil {
+			return closeRoots, err
+		}
+		templatesFS = sub
+	}
+
+	return closeRoots, nil
+}
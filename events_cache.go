@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"marianapparitions/model"
+	"marianapparitions/related"
+	"marianapparitions/repository"
+)
+
+// indexEvents caches the events loaded for the index and its exports,
+// which otherwise reload the whole dataset on every request.
+var indexEvents = &eventsCache{}
+
+type eventsCache struct {
+	ttl time.Duration // 0 disables the cache
+
+	mu       sync.Mutex
+	events   []model.Event
+	loadedAt time.Time
+	related  *related.Index // Of events, built on first use
+}
+
+// Get returns the cached events, reloading them when they are older than
+// the TTL. Callers must not modify the returned events.
+func (c *eventsCache) Get(ctx context.Context) ([]model.Event, error) {
+	if c.ttl <= 0 {
+		return repository.GetAllEventsContext(ctx, db)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.events != nil && time.Since(c.loadedAt) < c.ttl {
+		recordCacheLookup(ctx, "index_events", true)
+		return c.events, nil
+	}
+	recordCacheLookup(ctx, "index_events", false)
+
+	events, err := repository.GetAllEventsContext(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	c.events, c.loadedAt, c.related = events, time.Now(), nil
+	return events, nil
+}
+
+// Related returns the index of related events of the cached events.
+func (c *eventsCache) Related(ctx context.Context) (*related.Index, error) {
+	events, err := c.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.ttl <= 0 {
+		return related.NewIndex(events), nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.related == nil {
+		c.related = related.NewIndex(c.events) // Maybe reloaded since Get
+	}
+	return c.related, nil
+}
+
+// Invalidate makes the next Get reload the events, after they were edited.
+func (c *eventsCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events, c.related = nil, nil
+}
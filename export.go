@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"marianapparitions/export"
+	"marianapparitions/model"
+)
+
+// exportHeader names the columns of the export, with an approval column per
+// church of model.Churches.
+func exportHeader() []any {
+	header := []any{"Name", "Category", "Years", "Country", "Slug"}
+	for _, church := range model.Churches {
+		header = append(header, church+" approval")
+	}
+	return append(header, "Requests")
+}
+
+// handleExport downloads the index as filtered and sorted by the same query
+// parameters, as /export.csv or /export.xlsx.
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseIndexFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := filterEvents(r.Context(), filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	categories, err := loadCategories(r.Context(), filters.Locale)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	recordIndexQuery(r.Context(), r.URL.Path, filters, categories, len(events))
+
+	var out export.Writer
+	if strings.HasSuffix(r.URL.Path, ".xlsx") {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", `attachment; filename="marian-apparitions.xlsx"`)
+		out, err = export.NewXLSXWriter(w, "Marian Apparitions")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="marian-apparitions.csv"`)
+		out = export.NewCSVWriter(w)
+	}
+
+	// The response has started at this point, so errors can only be logged
+	if err := out.Write(exportHeader()); err != nil {
+		slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "error", err)
+		return
+	}
+	for _, e := range events {
+		row := []any{e.Name, e.Category, e.Years, e.Country, e.Slug()}
+		for _, church := range model.Churches {
+			row = append(row, e.GetApproverChurch(church))
+		}
+		row = append(row, len(e.Requests))
+		if err := out.Write(row); err != nil {
+			slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "error", err)
+			return
+		}
+	}
+	if err := out.Close(); err != nil {
+		slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package export writes tabular data as CSV or XLSX files. Both writers
+// stream rows to the underlying io.Writer as they are written.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Writer writes rows of cells. Cells are strings or ints.
+type Writer interface {
+	Write(row []any) error
+	// Close flushes buffered data and writes any trailer. It doesn't close
+	// the underlying io.Writer.
+	Close() error
+}
+
+// CSVWriter writes UTF-8 CSV with a byte order mark, so that spreadsheet
+// applications don't mangle accented names.
+type CSVWriter struct {
+	w       *csv.Writer
+	out     io.Writer
+	started bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), out: w}
+}
+
+func (cw *CSVWriter) Write(row []any) error {
+	if !cw.started {
+		cw.started = true
+		if _, err := io.WriteString(cw.out, "\uFEFF"); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(row))
+	for i, cell := range row {
+		if text, ok := cell.(string); ok {
+			record[i] = neutralizeFormula(text)
+		} else {
+			record[i] = cellString(cell)
+		}
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *CSVWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func cellString(cell any) string {
+	switch v := cell.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// neutralizeFormula prevents spreadsheet applications from evaluating
+// text cells as formulas (CSV injection). Numbers are left alone, so that
+// negative ones stay numbers.
+func neutralizeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
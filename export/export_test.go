@@ -0,0 +1,36 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	if err := w.Write([]any{"Our Lady of Šiluva", 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]any{"=HYPERLINK(\"x\")", 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]any{"-2+3+cmd|' /C calc'!A0", -1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\uFEFFOur Lady of Šiluva,3\n\"'=HYPERLINK(\"\"x\"\")\",0\n'-2+3+cmd|' /C calc'!A0,-1\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"marianapparitions/viewmodel"
+
+	"golang.org/x/text/language"
+)
+
+// indexFilters is the filter and sort state of the index, as given in the
+// query string. The index page and its exports share it.
+type indexFilters struct {
+	StartYear          int
+	EndYear            int
+	SortBy             string
+	SelectedCategories map[string]bool
+	// sort_by as given when it isn't a known sort, SortBy is then the default
+	InvalidSort string
+	// Locale of the collation of names, countries and categories
+	Locale language.Tag
+}
+
+func parseIndexFilters(r *http.Request) (indexFilters, error) {
+	if err := r.ParseForm(); err != nil {
+		return indexFilters{}, err
+	}
+
+	f := indexFilters{SelectedCategories: make(map[string]bool), Locale: requestLocale(r)}
+	f.StartYear, _ = strconv.Atoi(r.FormValue("start_year"))
+	f.EndYear, _ = strconv.Atoi(r.FormValue("end_year"))
+	f.SortBy = r.FormValue("sort_by")
+	if f.SortBy == "" {
+		f.SortBy = cfg.DefaultSort
+	}
+	if key, direction, ok := parseSort(f.SortBy); ok {
+		f.SortBy = key.Key + "_" + direction
+	} else {
+		f.InvalidSort, f.SortBy = f.SortBy, cfg.DefaultSort
+	}
+	selectedCatsSlice := r.Form["category"] // Multi-value
+	for _, c := range selectedCatsSlice {
+		f.SelectedCategories[c] = true
+	}
+
+	slog.DebugContext(r.Context(), "Index filters", "start_year", f.StartYear, "end_year", f.EndYear, "sort_by", f.SortBy, "categories", selectedCatsSlice)
+	return f, nil
+}
+
+// loadCategories returns the categories to filter on, sorted for locale
+// like the events.
+func loadCategories(ctx context.Context, locale language.Tag) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT DISTINCT category FROM events")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var categories []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	newCollator(locale).SortStrings(categories)
+	return categories, rows.Err()
+}
+
+// filterEvents fetches all events, then filters and sorts them in memory.
+func filterEvents(ctx context.Context, f indexFilters) ([]*viewmodel.EventViewModel, error) {
+	// We fetch all because complex string parsing for years is easier in Go
+	allEvents, err := indexEvents.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredEvents []*viewmodel.EventViewModel
+	for i := range allEvents {
+		e := &allEvents[i]
+		// Category Filter
+		if len(f.SelectedCategories) > 0 && !f.SelectedCategories[e.Category] {
+			continue
+		}
+
+		// Year Filter
+		if !e.MatchesYears(f.StartYear, f.EndYear) {
+			continue
+		}
+
+		filteredEvents = append(filteredEvents, viewmodel.NewEventVM(e))
+	}
+
+	if f.SortBy != "" {
+		applySorting(filteredEvents, f.SortBy, newCollator(f.Locale))
+	}
+
+	return filteredEvents, nil
+}
@@ -2,27 +2,23 @@ package main
 
 import (
 	"database/sql"
-	"log"
+	"io/fs"
+	"log/slog"
 	"marianapparitions/model"
-	"os"
+	"strconv"
 	"strings"
 )
 
-func initDB() error {
-	// Check if table exists
-	var name string
-	err := db.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name='events'").Scan(&name)
-	if err == sql.ErrNoRows {
-		// Table doesn't exist, create it
-		schema, err := os.ReadFile("schema.sql")
-		if err != nil {
-			return err
-		}
-		_, err = db.Exec(string(schema))
-		if err != nil {
-			return err
-		}
-	} else if err != nil {
+// initDB creates the missing tables and columns. With seed, an empty
+// database gets a few demo events.
+func initDB(seed bool) error {
+	// The schema only uses CREATE TABLE IF NOT EXISTS, so it is safe to run
+	// on every start: it creates whatever tables are missing.
+	schema, err := fs.ReadFile(appFS, "schema.sql")
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
 		return err
 	}
 
@@ -52,22 +48,71 @@ func initDB() error {
 	}
 
 	// Check for 'country' column and add if missing
-	var countryCol string
-	err = db.QueryRow("SELECT country FROM events LIMIT 1").Scan(&countryCol)
-	if err != nil && strings.Contains(err.Error(), "no such column") {
-		_, _ = db.Exec("ALTER TABLE events ADD COLUMN country TEXT")
+	addColumnIfMissing("events", "country", "TEXT")
+
+	// Citation fields on external_sources (older databases only have source_url)
+	for _, col := range []string{"title", "author", "publisher", "accessed_on"} {
+		addColumnIfMissing("external_sources", col, "TEXT")
 	}
 
-	if count == 0 {
+	if count == 0 && seed {
 		if err := seedData(); err != nil {
 			return err
 		}
 	}
 
-	return ensureSlugs()
+	if err := ensureSlugs(); err != nil {
+		return err
+	}
+	return ensureUniqueSlugs()
+}
+
+// addColumnIfMissing adds a column to an existing table when selecting it
+// fails with "no such column".
+func addColumnIfMissing(table, column, columnType string) {
+	var discard any
+	err := db.QueryRow("SELECT " + column + " FROM " + table + " LIMIT 1").Scan(&discard)
+	if err != nil && strings.Contains(err.Error(), "no such column") {
+		_, _ = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + columnType)
+	}
+}
+
+// takenSlugs returns the slugs in use, lowercased.
+func takenSlugs() (map[string]bool, error) {
+	rows, err := db.Query("SELECT slug FROM events WHERE slug IS NOT NULL AND slug <> ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		taken[strings.ToLower(slug)] = true
+	}
+	return taken, rows.Err()
+}
+
+// uniqueSlug returns base, or base suffixed with -2, -3... if it's already
+// taken, and marks the result as taken.
+func uniqueSlug(base string, taken map[string]bool) string {
+	slug := base
+	for n := 2; taken[strings.ToLower(slug)]; n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	taken[strings.ToLower(slug)] = true
+	return slug
 }
 
 func ensureSlugs() error {
+	taken, err := takenSlugs()
+	if err != nil {
+		return err
+	}
+
 	rows, err := db.Query("SELECT id, name FROM events WHERE slug IS NULL OR slug = ''")
 	if err != nil {
 		return err
@@ -92,17 +137,62 @@ func ensureSlugs() error {
 	defer stmt.Close()
 
 	for _, e := range toUpdate {
-		newSlug := e.Slug()
+		newSlug := uniqueSlug(e.Slug(), taken)
 		_, err := stmt.Exec(newSlug, e.ID)
 		if err != nil {
-			log.Printf("Failed to update slug for %s: %v", e.Name, err)
+			slog.Error("Failed to update slug", "event", e.Name, "error", err)
 		} else {
-			log.Printf("Updated slug for event: %s -> %s", e.Name, newSlug)
+			slog.Info("Updated slug", "event", e.Name, "slug", newSlug)
 		}
 	}
 	return nil
 }
 
+// ensureUniqueSlugs renames events sharing a slug (keeping it on the oldest
+// one), then adds the unique index that prevents new duplicates.
+func ensureUniqueSlugs() error {
+	rows, err := db.Query("SELECT id, slug FROM events WHERE slug IS NOT NULL AND slug <> '' ORDER BY id")
+	if err != nil {
+		return err
+	}
+	type eventSlug struct {
+		id   int
+		slug string
+	}
+	var duplicates []eventSlug
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var e eventSlug
+		if err := rows.Scan(&e.id, &e.slug); err != nil {
+			rows.Close()
+			return err
+		}
+		if seen[strings.ToLower(e.slug)] {
+			duplicates = append(duplicates, e)
+		}
+		seen[strings.ToLower(e.slug)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range duplicates {
+		newSlug := uniqueSlug(e.slug, seen)
+		if _, err := db.Exec("UPDATE events SET slug = ? WHERE id = ?", newSlug, e.id); err != nil {
+			return err
+		}
+		// The old slug still belongs to the oldest event, it's not a redirect
+		if _, err := db.Exec("DELETE FROM slug_history WHERE slug = ? AND event_id = ?", e.slug, e.id); err != nil {
+			return err
+		}
+		slog.Warn("Renamed duplicate slug", "event_id", e.id, "old_slug", e.slug, "slug", newSlug)
+	}
+
+	_, err = db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS events_slug_unique ON events (slug COLLATE NOCASE) WHERE slug IS NOT NULL AND slug <> ''")
+	return err
+}
+
 func seedData() error {
 	_, err := db.Exec(`INSERT INTO events (category, name, description, wikipedia_section_title, image_filename, years, country) VALUES
 	('Apparition', 'Our Lady of Guadalupe', 'A series of five Marian apparitions in December 1531, and the image on a cloak enshrined within the Basilica of Our Lady of Guadalupe in Mexico City.', 'Our_Lady_of_Guadalupe', 'guadalupe.jpg', '1531', 'Mexico'),
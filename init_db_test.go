@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestUniqueSlug(t *testing.T) {
+	taken := map[string]bool{"our-lady-of-lourdes": true, "our-lady-of-lourdes-2": true}
+
+	if got := uniqueSlug("Our-Lady-of-Lourdes", taken); got != "Our-Lady-of-Lourdes-3" {
+		t.Errorf("got %q, want a suffix on case-insensitive collisions", got)
+	}
+	if got := uniqueSlug("our-lady-of-akita", taken); got != "our-lady-of-akita" {
+		t.Errorf("got %q, want the base slug when it's free", got)
+	}
+	if !taken["our-lady-of-akita"] {
+		t.Error("the returned slug should be marked as taken")
+	}
+}
@@ -0,0 +1,119 @@
+// Package linkcheck verifies that outbound links (Wikipedia articles,
+// external sources) still resolve, so link rot can be caught offline.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Link is an outbound URL and where it comes from.
+type Link struct {
+	EventSlug string
+	Kind      string // "wikipedia" or "source"
+	URL       string
+}
+
+// Result is the outcome of checking a Link.
+type Result struct {
+	Link
+	StatusCode int   // 0 if the request failed before getting a response
+	Err        error // transport error, if any
+}
+
+// Dead reports whether the link should be considered broken.
+func (r Result) Dead() bool {
+	return r.Err != nil || r.StatusCode >= 400
+}
+
+// Checker checks links concurrently.
+type Checker struct {
+	Client      *http.Client
+	Concurrency int
+}
+
+// NewChecker returns a Checker with sensible defaults.
+func NewChecker() *Checker {
+	return &Checker{
+		Client:      &http.Client{Timeout: 15 * time.Second},
+		Concurrency: 4,
+	}
+}
+
+// Check checks every link and returns the results in the same order.
+func (c *Checker) Check(ctx context.Context, links []Link) []Result {
+	results := make([]Result, len(links))
+	concurrency := max(c.Concurrency, 1)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, l := range links {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.checkOne(ctx, l)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (c *Checker) checkOne(ctx context.Context, l Link) Result {
+	res := Result{Link: l}
+
+	status, err := c.do(ctx, http.MethodHead, l.URL)
+	// Some servers don't implement HEAD; retry those with GET
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = c.do(ctx, http.MethodGet, l.URL)
+	}
+	res.StatusCode, res.Err = status, err
+
+	return res
+}
+
+func (c *Checker) do(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "marianapparitions-check-links/1.0")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// WriteReport writes one tab-separated line per dead link and returns how
+// many there were.
+func WriteReport(w io.Writer, results []Result) (int, error) {
+	dead := 0
+	for _, r := range results {
+		if !r.Dead() {
+			continue
+		}
+		dead++
+
+		reason := fmt.Sprintf("HTTP %d", r.StatusCode)
+		if r.Err != nil {
+			reason = r.Err.Error()
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.EventSlug, r.Kind, r.URL, reason); err != nil {
+			return dead, err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "# %d checked, %d dead\n", len(results), dead)
+	return dead, err
+}
@@ -0,0 +1,52 @@
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newStubServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/en/wiki/Our_Lady_of_Lourdes", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	// Everything else is a 404
+	return httptest.NewServer(mux)
+}
+
+func TestCheck(t *testing.T) {
+	srv := newStubServer()
+	defer srv.Close()
+
+	links := []Link{
+		{EventSlug: "our-lady-of-lourdes", Kind: "wikipedia", URL: srv.URL + "/en/wiki/Our_Lady_of_Lourdes"},
+		{EventSlug: "our-lady-of-lourdes", Kind: "source", URL: srv.URL + "/get-only"},
+		{EventSlug: "our-lady-of-fatima", Kind: "source", URL: srv.URL + "/gone"},
+	}
+	results := NewChecker().Check(context.Background(), links)
+
+	for i, wantDead := range []bool{false, false, true} {
+		if results[i].Dead() != wantDead {
+			t.Errorf("%s: Dead() = %v, want %v (status %d, err %v)", results[i].URL, results[i].Dead(), wantDead, results[i].StatusCode, results[i].Err)
+		}
+	}
+
+	var buf bytes.Buffer
+	dead, err := WriteReport(&buf, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dead != 1 {
+		t.Errorf("dead = %d, want 1", dead)
+	}
+	if !strings.Contains(buf.String(), "our-lady-of-fatima\tsource\t"+srv.URL+"/gone\tHTTP 404") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+
+	"marianapparitions/lint"
+	"marianapparitions/repository"
+)
+
+// lintData reports data problems and fails if there are any, so it can
+// gate a data import.
+func lintData(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	mapsDir := fs.String("maps", "", "directory of the map images, one <slug>.png per event (default: static/images/maps of the app files)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var in lint.Input
+	var err error
+	if in.Events, err = repository.GetAllEventsContext(ctx, db); err != nil {
+		return err
+	}
+	sources, err := repository.GetAllSourcesContext(ctx, db)
+	if err != nil {
+		return err
+	}
+	for i := range in.Events {
+		for _, s := range sources {
+			if s.EventID == in.Events[i].ID {
+				in.Events[i].Sources = append(in.Events[i].Sources, s)
+			}
+		}
+	}
+	if in.OrphanRequests, err = repository.GetOrphanRequestsContext(ctx, db); err != nil {
+		return err
+	}
+	var mapFiles []string
+	if *mapsDir != "" {
+		mapFiles, err = filepath.Glob(filepath.Join(*mapsDir, "*.png"))
+	} else {
+		mapFiles, err = iofs.Glob(appFS, "static/images/maps/*.png")
+	}
+	if err != nil {
+		return err
+	}
+	for _, f := range mapFiles {
+		in.MapFiles = append(in.MapFiles, filepath.Base(f))
+	}
+
+	problems := lint.Check(in)
+	for _, p := range problems {
+		fmt.Fprintln(os.Stdout, p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s)", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package lint finds data problems in the apparition database: values the
+// app can't interpret, broken references, and missing or stray map images.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"marianapparitions/model"
+)
+
+// Problem is one issue found in the data.
+type Problem struct {
+	Subject string // What the problem is about, e.g. an event slug or a file name
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Subject + ": " + p.Message
+}
+
+// Input is the data to check.
+type Input struct {
+	Events         []model.Event // With their blocks, requests and sources loaded
+	OrphanRequests []model.Request
+	MapFiles       []string // Base names of the map images, e.g. "our-lady-of-akita.png"
+}
+
+// Check runs every check and returns the problems sorted by subject.
+func Check(in Input) []Problem {
+	var problems []Problem
+	report := func(subject, format string, args ...any) {
+		problems = append(problems, Problem{Subject: subject, Message: fmt.Sprintf(format, args...)})
+	}
+
+	slugs := make(map[string]bool)
+	slugOwners := make(map[string][]string)
+	for i := range in.Events {
+		e := &in.Events[i]
+		subject := e.SlugDB
+		if subject == "" {
+			subject = fmt.Sprintf("event #%d (%s)", e.ID, e.Name)
+		} else {
+			slugs[e.SlugDB] = true
+			// Case variants would collide in URLs too
+			key := strings.ToLower(e.SlugDB)
+			slugOwners[key] = append(slugOwners[key], e.Name)
+		}
+
+		// The rules the editor checks before saving
+		for _, fe := range e.Validate() {
+			report(subject, "%s", fe.Message)
+		}
+		for _, b := range e.Blocks {
+			for _, fe := range b.Validate() {
+				report(subject, "block #%d (%s): %s", b.ID, b.Title, fe.Message)
+			}
+		}
+		for _, r := range e.Requests {
+			for _, fe := range r.Validate() {
+				report(subject, "request #%d: %s", r.ID, fe.Message)
+			}
+		}
+		for _, s := range e.Sources {
+			for _, fe := range s.Validate() {
+				report(subject, "source #%d: %s", s.ID, fe.Message)
+			}
+		}
+
+		if len(e.Blocks) == 0 {
+			report(subject, "no blocks")
+		}
+	}
+
+	for slug, names := range slugOwners {
+		if len(names) > 1 {
+			report(slug, "duplicate slug shared by %s", strings.Join(names, ", "))
+		}
+	}
+
+	for _, r := range in.OrphanRequests {
+		report(fmt.Sprintf("request #%d", r.ID), "points at missing event #%d", r.EventID)
+	}
+
+	maps := make(map[string]bool)
+	for _, f := range in.MapFiles {
+		slug := strings.TrimSuffix(f, ".png")
+		maps[slug] = true
+		if !slugs[slug] {
+			report(f, "map doesn't match any slug")
+		}
+	}
+	for slug := range slugs {
+		if !maps[slug] {
+			report(slug, "no map in static/images/maps")
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Subject != problems[j].Subject {
+			return problems[i].Subject < problems[j].Subject
+		}
+		return problems[i].Message < problems[j].Message
+	})
+	return problems
+}
@@ -0,0 +1,43 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"marianapparitions/model"
+)
+
+func TestCheck(t *testing.T) {
+	in := Input{
+		Events: []model.Event{
+			{ID: 1, Name: "Our Lady of Akita", SlugDB: "our-lady-of-akita", Years: "1973",
+				Blocks: []model.EventBlock{{ID: 7, Title: "Verdict", AuthorityPosition: "approved"}}},
+			{ID: 2, Name: "Akita", SlugDB: "Our-Lady-of-Akita", Years: "1973-198x",
+				Blocks: []model.EventBlock{{ID: 8, Title: "Verdict", AuthorityPosition: "aproved"}}},
+			{ID: 3, Name: "Nameless", Years: "1900",
+				Requests: []model.Request{{ID: 5}}, Sources: []model.Source{{ID: 6, URL: "www.example.com"}}},
+		},
+		OrphanRequests: []model.Request{{ID: 4, EventID: 99}},
+		MapFiles:       []string{"our-lady-of-akita.png", "old-name.png"},
+	}
+
+	var got []string
+	for _, p := range Check(in) {
+		got = append(got, p.String())
+	}
+	want := []string{
+		`Our-Lady-of-Akita: block #8 (Verdict): unknown authority position "aproved"`,
+		`Our-Lady-of-Akita: no map in static/images/maps`,
+		`Our-Lady-of-Akita: years "1973-198x": can't parse "1973-198x"`,
+		`event #3 (Nameless): empty slug`,
+		`event #3 (Nameless): no blocks`,
+		`event #3 (Nameless): request #5: empty request`,
+		`event #3 (Nameless): source #6: url "www.example.com" is not an absolute http(s) URL`,
+		`old-name.png: map doesn't match any slug`,
+		`our-lady-of-akita: duplicate slug shared by Our Lady of Akita, Akita`,
+		`request #4: points at missing event #99`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+
+	"marianapparitions/dataset"
+)
+
+// loadDataset imports the files written by dumpDataset, matching events by slug.
+func loadDataset(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("load", flag.ContinueOnError)
+	dir := fs.String("dir", "dataset", "directory to read the event files from")
+	prune := fs.Bool("prune", false, "delete events that are not in the dataset")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := dataset.Read(*dir)
+	if err != nil {
+		return err
+	}
+
+	stats, err := dataset.Load(ctx, db, events, *prune)
+	if err != nil {
+		return err
+	}
+	slog.Info("Loaded dataset", "dir", *dir, "created", stats.Created, "updated", stats.Updated, "pruned", stats.Pruned)
+	return nil
+}
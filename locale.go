@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// collationLocales are the locales whose collation rules are used to sort
+// names, countries and categories. The first one is the default.
+var collationLocales = []language.Tag{
+	language.English,
+	language.French,
+	language.Spanish,
+	language.Portuguese,
+	language.Italian,
+	language.German,
+	language.Polish,
+	language.Croatian,
+	language.Lithuanian,
+	language.Dutch,
+}
+
+var localeMatcher = language.NewMatcher(collationLocales)
+
+// requestLocale picks the collation locale of a request from ?lang=, then
+// the Accept-Language header.
+func requestLocale(r *http.Request) language.Tag {
+	tag, _ := language.MatchStrings(localeMatcher, r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+	return tag
+}
+
+// newCollator returns a collator for locale. Collators aren't safe for
+// concurrent use, so each request creates its own.
+func newCollator(locale language.Tag) *collate.Collator {
+	return collate.New(locale, collate.IgnoreCase)
+}
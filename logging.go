@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/trace"
+
+	"marianapparitions/config"
+)
+
+// newLogger builds the application logger: text or JSON lines on w, and
+// when telemetry is enabled, the same records through the OTel logs bridge
+// (which forwards to the provider initTelemetry installs later).
+func newLogger(w io.Writer, c config.Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: logLevel(c.LogLevel)}
+	var console slog.Handler
+	if c.LogFormat == config.LogFormatJSON {
+		console = slog.NewJSONHandler(w, opts)
+	} else {
+		console = slog.NewTextHandler(w, opts)
+	}
+
+	var h slog.Handler = traceHandler{console}
+	if c.Telemetry != config.TelemetryOff {
+		// The bridge has no level of its own, don't export what isn't printed
+		h = fanoutHandler{h, leveledHandler{otelslog.NewHandler("marianapparitions"), opts.Level}}
+	}
+	return slog.New(h)
+}
+
+func logLevel(name string) slog.Level {
+	var l slog.Level
+	// Validated by config.Validate, unknown names fall back to info
+	_ = l.UnmarshalText([]byte(name))
+	return l
+}
+
+// traceHandler adds the trace and span IDs of the record's context, so log
+// lines can be matched with their traces.
+type traceHandler struct{ slog.Handler }
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
+
+type leveledHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h leveledHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= h.level.Level() && h.Handler.Enabled(ctx, l)
+}
+
+func (h leveledHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return leveledHandler{h.Handler.WithAttrs(attrs), h.level}
+}
+
+func (h leveledHandler) WithGroup(name string) slog.Handler {
+	return leveledHandler{h.Handler.WithGroup(name), h.level}
+}
+
+// fanoutHandler sends records to every handler that accepts their level.
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, r.Level) {
+			errs = append(errs, handler.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"marianapparitions/config"
+)
+
+func TestLoggerTraceIDs(t *testing.T) {
+	c := config.Default()
+	c.Telemetry = config.TelemetryOff
+	c.LogFormat = config.LogFormatJSON
+	c.LogLevel = "warn"
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, c)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	logger.InfoContext(ctx, "below the level")
+	logger.WarnContext(ctx, "slug renamed", "slug", "fatima")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("want exactly one JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["msg"] != "slug renamed" || line["slug"] != "fatima" {
+		t.Errorf("unexpected record %v", line)
+	}
+	if line["trace_id"] != sc.TraceID().String() || line["span_id"] != sc.SpanID().String() {
+		t.Errorf("trace_id/span_id = %v/%v, want %s/%s", line["trace_id"], line["span_id"], sc.TraceID(), sc.SpanID())
+	}
+}
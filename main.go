@@ -3,79 +3,151 @@ package main
 import (
 	"context"
 	"database/sql"
-	"html/template"
-	"log"
+	"io/fs"
+	"log/slog"
 	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
 	"strings"
+	"syscall"
 
+	"marianapparitions/assets"
+	"marianapparitions/citation"
+	"marianapparitions/config"
+	"marianapparitions/dbtrace"
 	"marianapparitions/repository"
 	"marianapparitions/viewmodel"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-const DEFAULT_DB_PATH = "./data.sqlite3"
-const DEFAULT_PORT = "8080"
-const DEFAULT_SORT = "year_desc"
+// cfg is the effective configuration, see the config package
+var cfg config.Config
 
 var db *sql.DB
 
-var SupportedSorts = []viewmodel.SupportedSort{
-	{Name: "Name", Slug: "name_asc", Orientation: "asc"},
-	{Name: "Name", Slug: "name_desc", Orientation: "desc"},
-	{Name: "Year", Slug: "year_asc", Orientation: "asc"},
-	{Name: "Year", Slug: "year_desc", Orientation: "desc"},
-	{Name: "Category", Slug: "category_asc", Orientation: "asc"},
-	{Name: "Category", Slug: "category_desc", Orientation: "desc"},
-}
+var staticAssets *assets.Server
 
 func main() {
-	ctx := context.Background()
+	// Canceled on SIGINT/SIGTERM (e.g. systemd restarts), which stops the
+	// server gracefully and lets the deferred cleanups run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Deferred first so it runs last, after the DB and telemetry are closed
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 
-	shutdown, err := initTelemetry(ctx)
+	var args []string
+	var err error
+	cfg, args, err = config.Load(os.Args[0], os.Args[1:])
 	if err != nil {
-		log.Printf("Warning: failed to initialize telemetry: %v", err)
-	} else {
-		defer func() {
-			if err := shutdown(ctx); err != nil {
-				log.Printf("Warning: telemetry shutdown error: %v", err)
-			}
-		}()
+		slog.Error("Failed to load configuration", "error", err)
+		exitCode = 1
+		return
+	}
+	if err := cfg.Validate(supportedSortSlugs()); err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		exitCode = 1
+		return
 	}
+	slog.SetDefault(newLogger(os.Stderr, cfg))
 
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = DEFAULT_DB_PATH
+	// `config show` must work even when the database can't be opened
+	if len(args) > 0 && args[0] == "config" {
+		if err := configCommand(args[1:]); err != nil {
+			slog.Error("Command failed", "command", "config", "error", err)
+			exitCode = 1
+		}
+		return
 	}
-	db, err = sql.Open("sqlite3", dbPath)
+
+	closeFiles, err := setupFiles()
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("Failed to open files", "error", err)
+		exitCode = 1
+		return
+	}
+	defer closeFiles()
+
+	if cfg.Telemetry != config.TelemetryOff {
+		shutdown, err := initTelemetry(ctx, cfg.Telemetry, cfg.TraceSampleRatio)
+		if err != nil {
+			slog.Warn("Failed to initialize telemetry", "error", err)
+		} else {
+			defer func() {
+				// ctx may be canceled by now, the exporters still need time to
+				// flush. This comes after draining requests, see telemetryFlushTimeout.
+				flushCtx, cancel := context.WithTimeout(context.Background(), telemetryFlushTimeout)
+				defer cancel()
+				if err := shutdown(flushCtx); err != nil {
+					slog.Warn("Telemetry shutdown error", "error", err)
+				}
+			}()
+		}
 	}
+
+	// Every query is traced, see the dbtrace package
+	db = dbtrace.OpenDB(&sqlite3.SQLiteDriver{}, cfg.DBPath, "sqlite")
 	defer db.Close()
 
-	if err := initDB(); err != nil {
-		log.Fatal(err)
+	// `load` into a fresh database must give the dataset, without demo events
+	seed := len(args) == 0 || args[0] != "load"
+	if err := initDB(seed); err != nil {
+		slog.Error("Failed to initialize the database", "path", cfg.DBPath, "error", err)
+		exitCode = 1
+		return
+	}
+
+	// Subcommands (e.g. `marianapparitions check-links`) run instead of the server
+	if len(args) > 0 {
+		if err := runCommand(ctx, args[0], args[1:]); err != nil {
+			slog.Error("Command failed", "command", args[0], "error", err)
+			exitCode = 1
+		}
+		return
+	}
+
+	staticFS, err := fs.Sub(appFS, "static")
+	if err != nil {
+		slog.Error("Failed to open static files", "error", err)
+		exitCode = 1
+		return
 	}
+	staticAssets = assets.New(staticFS, "/static/")
+	staticAssets.MaxAge = cfg.Cache.StaticMaxAge
+	indexEvents.ttl = cfg.Cache.EventsTTL
 
 	mux := http.NewServeMux()
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	mux.Handle("/static/", http.StripPrefix("/static/", staticAssets))
+	mux.HandleFunc("/shrines", handleShrines)
+	mux.HandleFunc("/compare", handleCompare)
+	mux.HandleFunc("/timeline", handleTimeline)
+	mux.HandleFunc("/stats", handleStats)
+	mux.HandleFunc("/stats.json", handleStats)
+	mux.HandleFunc("/export.csv", handleExport)
+	mux.HandleFunc("/export.xlsx", handleExport)
+	// The editor, see admin.go
+	admin := newAdminHandler()
+	mux.Handle("/admin/", admin)
+	mux.Handle("/api/", admin)
 	mux.HandleFunc("/", handleIndexOrView)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = DEFAULT_PORT
+	if err := serve(ctx, otelhttp.NewHandler(mux, "marianapparitions")); err != nil {
+		slog.Error("Server error", "error", err)
+		exitCode = 1
 	}
-
-	log.Printf("Server starting on http://localhost:%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, otelhttp.NewHandler(mux, "marianapparitions")))
 }
 
 
 func handleIndexOrView(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
+	// /index.json is the JSON of the index, see render
+	if r.URL.Path != "/" && r.URL.Path != "/index.json" {
 		// Assume it's a slug if not root
 		handleView(w, r)
 		return
@@ -84,96 +156,124 @@ func handleIndexOrView(w http.ResponseWriter, r *http.Request) {
 	// Specific to the index:
 
 	// 1. Parse Filters
-	if err := r.ParseForm(); err != nil {
+	filters, err := parseIndexFilters(r)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	startYear, _ := strconv.Atoi(r.FormValue("start_year"))
-	endYear, _ := strconv.Atoi(r.FormValue("end_year"))
-	sortBy := r.FormValue("sort_by")
-	if sortBy == "" {
-		sortBy = DEFAULT_SORT // Default sort (see repository.GetAllEvents()'s SQL query)
-	}
-	selectedCatsSlice := r.Form["category"] // Multi-value
-	selectedCats := make(map[string]bool)
-	for _, c := range selectedCatsSlice {
-		selectedCats[c] = true
-	}
-
-	log.Println("Filters - StartYear:", startYear, "EndYear:", endYear, "SortBy:", sortBy, "Categories:", selectedCatsSlice)
-
-	// 2. Fetch Data (All Events)
-	// We fetch all because complex string parsing for years is easier in Go
-	allEvents, err := repository.GetAllEvents(db)
+	// 2. Fetch Categories for Dropdown/Checkboxes
+	categories, err := loadCategories(r.Context(), filters.Locale)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// 3. Fetch Categories for Dropdown/Checkboxes
-	catRows, err := db.Query("SELECT DISTINCT category FROM events ORDER BY category")
+	// 3. Fetch, Filter and Sort Events
+	filteredEvents, err := filterEvents(r.Context(), filters)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer catRows.Close()
-	var categories []string
-	for catRows.Next() {
-		var c string
-		if err := catRows.Scan(&c); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		categories = append(categories, c)
+
+	recordIndexQuery(r.Context(), "/", filters, categories, len(filteredEvents))
+	recordPageView(r.Context(), "/", "")
+
+	// 4. Render
+	viewModel := &viewmodel.IndexViewModel{
+		Events:             filteredEvents,
+		Categories:         categories,
+		SelectedCategories: filters.SelectedCategories,
+		StartYear:          filters.StartYear,
+		EndYear:            filters.EndYear,
+		SupportedSorts:     supportedSorts(),
+		CurrentSort:        filters.SortBy,
+		InvalidSort:        filters.InvalidSort,
+		FilterQuery:        buildQueryMap(r.URL.Query()),
+		RawQuery:           r.URL.Query().Encode(),
 	}
+	render(w, r, "index.html", viewModel)
+}
 
-	// 4. Apply Filters in Memory
-	var filteredEvents []*viewmodel.EventViewModel
-	for i := range allEvents {
-		e := &allEvents[i]
-		// Category Filter
-		if len(selectedCats) > 0 && !selectedCats[e.Category] {
-			continue
-		}
+// maxRelated bounds the related apparitions listed on an event's page
+const maxRelated = 5
 
-		// Year Filter
-		if !e.MatchesYears(startYear, endYear) {
-			continue
-		}
+func handleView(w http.ResponseWriter, r *http.Request) {
+	slug := strings.TrimPrefix(r.URL.Path, "/")
+	if base, format, ok := strings.Cut(slug, "/citations."); ok {
+		handleCitations(w, r, base, format)
+		return
+	}
+
+	// /{slug}.json is the JSON of the event, see render
+	slug, jsonSuffix := strings.CutSuffix(slug, ".json")
 
-		filteredEvents = append(filteredEvents, viewmodel.NewEventVM(e))
+	e, err := repository.GetEventBySlugContext(r.Context(), db, slug)
+	if err == sql.ErrNoRows {
+		suffix := ""
+		if jsonSuffix {
+			suffix = ".json"
+		}
+		redirectToCurrentSlug(w, r, slug, suffix)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	// 5. Apply Sorting
-	if sortBy != "" {
-		applySorting[*viewmodel.EventViewModel](filteredEvents, sortBy)
+	index, err := indexEvents.Related(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	viewModel := viewmodel.NewEventVM(&e)
+	viewModel.Related = viewmodel.NewRelatedEvents(index.Related(e.ID, maxRelated))
 
-	// 6. Render
-	viewModel := &viewmodel.IndexViewModel{
-		Events:             filteredEvents,
-		Categories:         categories,
-		SelectedCategories: selectedCats,
-		StartYear:          startYear,
-		EndYear:            endYear,
-		SupportedSorts:     SupportedSorts,
-		CurrentSort:        sortBy,
-		FilterQuery:        buildQueryMap(r.URL.Query()),
+	// The index links here with its query string, to go through the same
+	// list (filtered and sorted like the index) from the event's page
+	filters, err := parseIndexFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	tmpl, err := template.New("index.html").ParseFiles("templates/index.html")
+	list, err := filterEvents(r.Context(), filters)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	tmpl.Execute(w, viewModel)
+	viewModel.List = viewmodel.NewListNavigation(list, e.ID, r.URL.Query().Encode())
+
+	recordPageView(r.Context(), "/{slug}", slug)
+	render(w, r, "view.html", viewModel)
 }
 
-func handleView(w http.ResponseWriter, r *http.Request) {
-	slug := strings.TrimPrefix(r.URL.Path, "/")
+func handleShrines(w http.ResponseWriter, r *http.Request) {
+	allShrines, err := repository.GetAllShrinesContext(r.Context(), db)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	e, err := repository.GetEventBySlug(db, slug)
-	if err == sql.ErrNoRows {
+	viewModel := &viewmodel.ShrineIndexViewModel{
+		OnlyRequested: r.URL.Query().Get("requested") != "",
+	}
+	for _, s := range allShrines {
+		if viewModel.OnlyRequested && !s.BuiltAtMarysRequest {
+			continue
+		}
+		viewModel.Shrines = append(viewModel.Shrines, s)
+	}
+
+	recordPageView(r.Context(), "/shrines", "")
+	renderTemplate(w, r, "shrines.html", viewModel)
+}
+
+// redirectToCurrentSlug permanently redirects old slugs, and case or
+// trailing-slash variants, to the canonical URL of the event. It answers
+// 404 if the slug doesn't refer to any event.
+func redirectToCurrentSlug(w http.ResponseWriter, r *http.Request, slug string, suffix string) {
+	current, err := repository.ResolveSlugContext(r.Context(), db, slug)
+	if err == sql.ErrNoRows || current == slug {
 		http.NotFound(w, r)
 		return
 	} else if err != nil {
@@ -181,10 +281,38 @@ func handleView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tmpl, err := template.ParseFiles("templates/view.html")
-	if err != nil {
+	target := "/" + current + suffix
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// handleCitations exports an event's sources as BibTeX ("bib") or CSL-JSON ("json"),
+// e.g. /our-lady-of-fatima/citations.bib
+func handleCitations(w http.ResponseWriter, r *http.Request, slug string, format string) {
+	if format != "bib" && format != "json" {
+		http.NotFound(w, r)
+		return
+	}
+
+	e, err := repository.GetEventBySlugContext(r.Context(), db, slug)
+	if err == sql.ErrNoRows {
+		redirectToCurrentSlug(w, r, slug, "/citations."+format)
+		return
+	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	tmpl.Execute(w, e)
+
+	if format == "bib" {
+		w.Header().Set("Content-Type", "application/x-bibtex; charset=utf-8")
+		err = citation.WriteBibTeX(w, e.Slug(), e.Sources)
+	} else {
+		w.Header().Set("Content-Type", "application/vnd.citationstyles.csl+json; charset=utf-8")
+		err = citation.WriteCSLJSON(w, e.Slug(), e.Sources)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write citations", "slug", e.Slug(), "error", err)
+	}
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Application metrics. The instruments come from the global MeterProvider,
+// which forwards to the one installed by initTelemetry (or drops everything
+// when telemetry is disabled). HTTP request metrics themselves are recorded
+// by otelhttp; these cover what happens inside the handlers.
+var meter = otel.Meter("marianapparitions")
+
+var (
+	pageViews = must(meter.Int64Counter("app.page.views",
+		metric.WithDescription("Pages rendered, per route and event slug"),
+		metric.WithUnit("{view}")))
+	filterUsage = must(meter.Int64Counter("app.index.filter.usage",
+		metric.WithDescription("Index and export requests using a given filter"),
+		metric.WithUnit("{request}")))
+	sortUsage = must(meter.Int64Counter("app.index.sort.usage",
+		metric.WithDescription("Index and export requests per sort key"),
+		metric.WithUnit("{request}")))
+	resultSize = must(meter.Int64Histogram("app.index.results",
+		metric.WithDescription("Number of events matching the index filters"),
+		metric.WithUnit("{event}"),
+		metric.WithExplicitBucketBoundaries(0, 1, 5, 10, 25, 50, 100, 250)))
+	renderDuration = must(meter.Float64Histogram("app.template.render.duration",
+		metric.WithDescription("Time spent parsing and executing a template"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1)))
+	cacheRequests = must(meter.Int64Counter("app.cache.requests",
+		metric.WithDescription("Cache lookups, by cache and result (hit or miss); hit ratio is hits / total"),
+		metric.WithUnit("{request}")))
+)
+
+func must[T any](instrument T, err error) T {
+	if err != nil {
+		otel.Handle(err)
+	}
+	return instrument
+}
+
+func recordPageView(ctx context.Context, route, slug string) {
+	attrs := []attribute.KeyValue{attribute.String("http.route", route)}
+	if slug != "" {
+		attrs = append(attrs, attribute.String("app.event.slug", slug))
+	}
+	pageViews.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// recordIndexQuery records which filters and sort an index (or export)
+// request used and how many events it matched. Selected categories that
+// aren't in categories (see loadCategories) are recorded as "other", so
+// clients can't create new series. f.SortBy is always a known sort.
+func recordIndexQuery(ctx context.Context, route string, f indexFilters, categories []string, results int) {
+	routeAttr := attribute.String("http.route", route)
+	used := func(filter string) {
+		filterUsage.Add(ctx, 1, metric.WithAttributes(routeAttr, attribute.String("app.filter.name", filter)))
+	}
+	if f.StartYear != 0 {
+		used("start_year")
+	}
+	if f.EndYear != 0 {
+		used("end_year")
+	}
+	for c := range f.SelectedCategories {
+		if !slices.Contains(categories, c) {
+			c = "other"
+		}
+		filterUsage.Add(ctx, 1, metric.WithAttributes(routeAttr,
+			attribute.String("app.filter.name", "category"),
+			attribute.String("app.filter.value", c)))
+	}
+	sortUsage.Add(ctx, 1, metric.WithAttributes(routeAttr, attribute.String("app.sort.key", f.SortBy)))
+	resultSize.Record(ctx, int64(results), metric.WithAttributes(routeAttr,
+		attribute.Bool("app.filtered", f.StartYear != 0 || f.EndYear != 0 || len(f.SelectedCategories) > 0)))
+}
+
+func recordRender(ctx context.Context, name string, start time.Time, err error) {
+	attrs := []attribute.KeyValue{attribute.String("app.template.name", name)}
+	if err != nil {
+		attrs = append(attrs, attribute.String("error.type", "template"))
+	}
+	renderDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+func recordCacheLookup(ctx context.Context, cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("app.cache.name", cache),
+		attribute.String("app.cache.result", result)))
+}
@@ -1,9 +1,13 @@
 package model
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"golang.org/x/text/runes"
@@ -12,17 +16,20 @@ import (
 )
 
 type Event struct {
-	ID                    int
-	Category              string
-	Name                  string
-	Description           string
-	WikipediaSectionTitle string
-	ImageFilename         string
-	Years                 string
-	SlugDB                string // Maps to 'slug' column
-	Country               string
-	Requests              []Request
-	Blocks                []EventBlock
+	ID                    int          `json:"id"`
+	Category              string       `json:"category"`
+	Name                  string       `json:"name"`
+	Description           string       `json:"description"`
+	WikipediaSectionTitle string       `json:"wikipedia_section_title"`
+	ImageFilename         string       `json:"image_filename,omitempty"`
+	Years                 string       `json:"years"`
+	SlugDB                string       `json:"slug"` // Maps to 'slug' column
+	Country               string       `json:"country"`
+	Requests              []Request    `json:"requests"`
+	Blocks                []EventBlock `json:"blocks"`
+	Sources               []Source     `json:"sources,omitempty"`
+	Media                 []Media      `json:"media,omitempty"`
+	Shrines               []Shrine     `json:"shrines,omitempty"`
 }
 
 // Slug returns the identifier used in URLs.
@@ -49,6 +56,199 @@ func (e *Event) Slug() string {
 	return s
 }
 
+// FirstImage returns the first image of the gallery, or nil if there is none.
+func (e *Event) FirstImage() *Media {
+	for i := range e.Media {
+		if e.Media[i].IsImage() {
+			return &e.Media[i]
+		}
+	}
+	return nil
+}
+
+// WikipediaEndpoint is the default prefix of Wikipedia article URLs.
+// "{lang}" is replaced by the language code (e.g. "en", "fr").
+const WikipediaEndpoint = "https://{lang}.wikipedia.org/wiki/"
+
+// WikipediaLanguage is the language WikipediaSectionTitle is written in,
+// and so of the Wikipedia the pages link to: titles differ between
+// languages, the title would be wrong on the others.
+const WikipediaLanguage = "en"
+
+// WikipediaURL returns the link to the event's Wikipedia article (and
+// section, if WikipediaSectionTitle contains a "#") in the given language.
+// It returns "" if the event has no Wikipedia title.
+func (e *Event) WikipediaURL(lang string) string {
+	return e.WikipediaURLAt(WikipediaEndpoint, lang)
+}
+
+// WikipediaURLAt is like WikipediaURL but builds the URL on a custom
+// endpoint, which lets the link checker target a mirror or a stub server.
+func (e *Event) WikipediaURLAt(endpoint, lang string) string {
+	title := strings.TrimSpace(e.WikipediaSectionTitle)
+	if title == "" {
+		return ""
+	}
+
+	// Wikipedia uses underscores in place of spaces, both in titles and anchors
+	title = strings.ReplaceAll(title, " ", "_")
+	article, section, hasSection := strings.Cut(title, "#")
+
+	u := strings.ReplaceAll(endpoint, "{lang}", lang) + url.PathEscape(article)
+	if hasSection && section != "" {
+		u += "#" + url.PathEscape(section)
+	}
+	return u
+}
+
+// Churches are the churches whose verdicts are summarized (compare, stats).
+// They are matched as substrings of EventBlock.ChurchAuthority.
+var Churches = []string{"Catholic", "Orthodox", "Anglican"}
+
+// Verdict returns the first block giving a position of the church, or nil.
+func (e *Event) Verdict(churchNameSubstr string) *EventBlock {
+	for i, block := range e.Blocks {
+		if block.AuthorityPosition != "" && strings.Contains(block.ChurchAuthority, churchNameSubstr) {
+			return &e.Blocks[i]
+		}
+	}
+	return nil
+}
+
+// IsApproved reports whether any church approved the event, see
+// EventBlock.IsApproval.
+func (e *Event) IsApproved() bool {
+	for i := range e.Blocks {
+		if e.Blocks[i].IsApproval() {
+			return true
+		}
+	}
+	return false
+}
+
+// ApprovalLevel is the most favourable verdict of any church on the event,
+// see EventBlock.ApprovalLevel. It is 0 when no church took a position.
+func (e *Event) ApprovalLevel() int {
+	level := 0
+	for i := range e.Blocks {
+		level = max(level, e.Blocks[i].ApprovalLevel())
+	}
+	return level
+}
+
+// LastUpdated returns when the event's content last changed, the zero time
+// if unknown.
+func (e *Event) LastUpdated() time.Time {
+	var last time.Time
+	for _, b := range e.Blocks {
+		if b.UpdatedAt.After(last) {
+			last = b.UpdatedAt
+		}
+	}
+	return last
+}
+
+// FirstYear returns the start of the event's first parseable year range,
+// 0 if none.
+func (e *Event) FirstYear() int {
+	ranges, _ := e.ParseYears()
+	if len(ranges) == 0 {
+		return 0
+	}
+	return ranges[0].Start
+}
+
+// YearRange is one comma-separated part of an event's Years, e.g. "1531",
+// "1981-1983" or "1981-present".
+type YearRange struct {
+	Start   int
+	End     int  // Same as Start for a single year, 0 if Ongoing
+	Ongoing bool // "present" ranges
+}
+
+// ParseYears parses e.Years. Unparseable parts are skipped and reported in
+// the returned error, so callers can still use the valid ranges.
+func (e *Event) ParseYears() ([]YearRange, error) {
+	// Normalize input: replace en-dash (–) and em-dash (—) with standard hyphen (-)
+	normalizedYears := strings.ReplaceAll(e.Years, "–", "-")
+	normalizedYears = strings.ReplaceAll(normalizedYears, "—", "-")
+
+	if strings.TrimSpace(normalizedYears) == "" {
+		return nil, errors.New("no years")
+	}
+
+	var ranges []YearRange
+	var invalid []string
+	for _, part := range strings.Split(normalizedYears, ",") {
+		part = strings.TrimSpace(part)
+		if r, ok := parseYearRange(part); ok {
+			ranges = append(ranges, r)
+		} else {
+			invalid = append(invalid, strconv.Quote(part))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return ranges, fmt.Errorf("can't parse %s", strings.Join(invalid, ", "))
+	}
+	return ranges, nil
+}
+
+func parseYearRange(part string) (YearRange, bool) {
+	if !strings.Contains(part, "-") {
+		// Single year: "1531"
+		year, err := strconv.Atoi(part)
+		if err != nil {
+			return YearRange{}, false
+		}
+		return YearRange{Start: year, End: year}, true
+	}
+
+	// Range: "1981-1983" or "1981-present"
+	rangeParts := strings.Split(part, "-")
+	if len(rangeParts) != 2 {
+		return YearRange{}, false
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(rangeParts[0]))
+	if err != nil {
+		return YearRange{}, false
+	}
+	endPart := strings.TrimSpace(rangeParts[1])
+	if strings.EqualFold(endPart, "present") {
+		return YearRange{Start: start, Ongoing: true}, true
+	}
+	end, err := strconv.Atoi(endPart)
+	if err != nil || end < start {
+		return YearRange{}, false
+	}
+	return YearRange{Start: start, End: end}, true
+}
+
+// Century returns the century of a year, 1901-2000 being the 20th, or 0
+// for years before the common era.
+func Century(year int) int {
+	if year <= 0 {
+		return 0
+	}
+	return (year-1)/100 + 1
+}
+
+// CenturyName returns e.g. "20th century".
+func CenturyName(century int) string {
+	suffix := "th"
+	if century%100 < 11 || century%100 > 13 {
+		switch century % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(century) + suffix + " century"
+}
+
 func (e *Event) MatchesYears(filterStart, filterEnd int) bool {
 	// If no filter provided, everything matches
 	if filterStart == 0 && filterEnd == 0 {
@@ -60,54 +260,17 @@ func (e *Event) MatchesYears(filterStart, filterEnd int) bool {
 		filterEnd = 10000 // Far future
 	}
 
-	// Normalize input: replace en-dash (–) and em-dash (—) with standard hyphen (-)
-	normalizedYears := strings.ReplaceAll(e.Years, "–", "-")
-	normalizedYears = strings.ReplaceAll(normalizedYears, "—", "-")
+	// Unparseable parts are ignored
+	ranges, _ := e.ParseYears()
+	for _, r := range ranges {
+		end := r.End
+		if r.Ongoing {
+			end = 10000 // treated as start-10000
+		}
 
-	parts := strings.Split(normalizedYears, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if strings.Contains(part, "-") {
-			// Range: "1981-1983"
-			rangeParts := strings.Split(part, "-")
-			if len(rangeParts) != 2 {
-				continue
-			}
-			start, err1 := strconv.Atoi(strings.TrimSpace(rangeParts[0]))
-			end, err2 := strconv.Atoi(strings.TrimSpace(rangeParts[1]))
-			if err1 != nil || err2 != nil {
-				continue
-			}
-
-			// Check overlap: max(start, filterStart) <= min(end, filterEnd)
-			if start <= filterEnd && end >= filterStart {
-				return true
-			}
-		} else {
-			// Single year: "1531"
-			// Check if it's "1981-present" style but normalized
-			if strings.Contains(part, "present") {
-				// Handle "1981-present"
-				subParts := strings.Split(part, "-")
-				if len(subParts) >= 1 {
-					start, err := strconv.Atoi(strings.TrimSpace(subParts[0]))
-					if err == nil {
-						// treated as start-10000
-						if start <= filterEnd && 10000 >= filterStart {
-							return true
-						}
-					}
-				}
-				continue
-			}
-
-			year, err := strconv.Atoi(part)
-			if err != nil {
-				continue
-			}
-			if year >= filterStart && year <= filterEnd {
-				return true
-			}
+		// Check overlap: max(start, filterStart) <= min(end, filterEnd)
+		if r.Start <= filterEnd && end >= filterStart {
+			return true
 		}
 	}
 	return false
@@ -1,11 +1,72 @@
 package model
 
+import "time"
+
 type EventBlock struct {
-	ID       int
-	Title    string
-	Content  string
-	EventID  int
-	Ordering int
-	ChurchAuthority string
-	AuthorityPosition string
+	ID                int       `json:"id"`
+	Title             string    `json:"title"`
+	Content           string    `json:"content"`
+	EventID           int       `json:"event_id"`
+	Ordering          int       `json:"ordering"`
+	Language          string    `json:"language"`
+	ChurchAuthority   string    `json:"church_authority,omitempty"`
+	AuthorityPosition string    `json:"authority_position,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at,omitzero"` // zero if unknown
+}
+
+// AuthorityPositions are the known values of EventBlock.AuthorityPosition.
+// An empty position means the block isn't a verdict.
+var AuthorityPositions = []string{
+	// Before the 2024 norms
+	"approved", // Constat de supernaturalitate
+	"pending",  // No judgement yet, or investigation in progress
+	"neutral",  // Non constat de supernaturalitate
+	"rejected", // Constat de non supernaturalitate
+	// Since the 2024 norms
+	"nihil_obstat",
+	"prae_oculis_habeatur",
+	"curatur",
+	"sub_mandato",
+	"prohibetur_et_obstruatur",
+	"declaratio_de_non_supernaturalitate",
+}
+
+// approvalLevels rank the authority positions, from the most favourable.
+// Positions of the old and 2024 norms that mean about the same share a level.
+var approvalLevels = map[string]int{
+	"approved":                            6,
+	"nihil_obstat":                        6,
+	"prae_oculis_habeatur":                5,
+	"pending":                             4,
+	"curatur":                             4,
+	"sub_mandato":                         4,
+	"neutral":                             3,
+	"prohibetur_et_obstruatur":            2,
+	"rejected":                            1,
+	"declaratio_de_non_supernaturalitate": 1,
+}
+
+// ApprovalLevel ranks the block's authority position, higher being more
+// favourable. It is 0 for blocks that aren't a verdict.
+func (b *EventBlock) ApprovalLevel() int {
+	return approvalLevels[b.AuthorityPosition]
+}
+
+// IsApproval reports whether the block is a verdict approving the event:
+// "approved" under the old norms, "nihil_obstat" under the 2024 ones.
+func (b *EventBlock) IsApproval() bool {
+	return b.ApprovalLevel() >= approvalLevels["approved"]
+}
+
+// IsKnownAuthorityPosition reports whether position is empty or one of AuthorityPositions.
+func IsKnownAuthorityPosition(position string) bool {
+	if position == "" {
+		return true
+	}
+	for _, p := range AuthorityPositions {
+		if p == position {
+			return true
+		}
+	}
+	return false
 }
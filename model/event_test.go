@@ -0,0 +1,28 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYears(t *testing.T) {
+	e := Event{Years: "1531, 1981–1983, 1981-present, 19xx"}
+	ranges, err := e.ParseYears()
+	want := []YearRange{{Start: 1531, End: 1531}, {Start: 1981, End: 1983}, {Start: 1981, Ongoing: true}}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("ranges = %+v, want %+v", ranges, want)
+	}
+	if err == nil {
+		t.Error("expected an error for \"19xx\"")
+	}
+}
+
+func TestMatchesYears(t *testing.T) {
+	medjugorje := Event{Years: "1981–present"}
+	if !medjugorje.MatchesYears(2000, 2010) {
+		t.Error("ongoing range should match a later filter")
+	}
+	if medjugorje.MatchesYears(1900, 1950) {
+		t.Error("ongoing range shouldn't match an earlier filter")
+	}
+}
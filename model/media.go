@@ -0,0 +1,63 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+	MediaAudio = "audio"
+)
+
+// Media is an image, video or audio item in an event's gallery.
+// Maps to the 'media' table.
+type Media struct {
+	ID       int    `json:"id"`
+	EventID  int    `json:"event_id"`
+	Kind     string `json:"kind"`   // One of MediaImage, MediaVideo, MediaAudio
+	Source   string `json:"source"` // File name under static/images/, or an absolute URL
+	Caption  string `json:"caption,omitempty"`
+	Credit   string `json:"credit,omitempty"`
+	License  string `json:"license,omitempty"`
+	Ordering int    `json:"ordering"`
+}
+
+func (m *Media) IsImage() bool { return m.Kind == MediaImage }
+func (m *Media) IsVideo() bool { return m.Kind == MediaVideo }
+func (m *Media) IsAudio() bool { return m.Kind == MediaAudio }
+
+// Src returns the URL to use in src/href attributes.
+func (m *Media) Src() string {
+	if strings.HasPrefix(m.Source, "http://") || strings.HasPrefix(m.Source, "https://") {
+		return m.Source
+	}
+	return "/static/images/" + strings.TrimPrefix(m.Source, "/")
+}
+
+// YouTubeEmbedURL returns the privacy-enhanced embed URL of a YouTube
+// video, or "" if Source isn't a YouTube link.
+func (m *Media) YouTubeEmbedURL() string {
+	u, err := url.Parse(m.Source)
+	if err != nil {
+		return ""
+	}
+
+	var id string
+	switch strings.TrimPrefix(u.Hostname(), "www.") {
+	case "youtube.com", "m.youtube.com":
+		if u.Path == "/watch" {
+			id = u.Query().Get("v")
+		} else if rest, ok := strings.CutPrefix(u.Path, "/embed/"); ok {
+			id = rest
+		}
+	case "youtu.be":
+		id = strings.TrimPrefix(u.Path, "/")
+	}
+	if id == "" || strings.Contains(id, "/") {
+		return ""
+	}
+
+	return "https://www.youtube-nocookie.com/embed/" + url.PathEscape(id)
+}
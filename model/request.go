@@ -1,7 +1,7 @@
 package model
 
 type Request struct {
-	ID      int
-	EventID int
-	Request string
+	ID      int    `json:"id"`
+	EventID int    `json:"event_id"`
+	Request string `json:"request"`
 }
@@ -0,0 +1,32 @@
+package model
+
+import "fmt"
+
+// Shrine is a church, chapel or sanctuary tied to an apparition.
+// Maps to the 'shrines' table.
+type Shrine struct {
+	ID                  int     `json:"id"`
+	EventID             int     `json:"event_id"`
+	Name                string  `json:"name"`
+	Website             string  `json:"website,omitempty"`
+	Latitude            float64 `json:"latitude"`
+	Longitude           float64 `json:"longitude"`
+	HasCoordinates      bool    `json:"has_coordinates"`        // false when latitude/longitude are NULL
+	FoundedYear         int     `json:"founded_year,omitempty"` // 0 if unknown
+	BuiltAtMarysRequest bool    `json:"built_at_marys_request"`
+	RequestID           int     `json:"request_id,omitempty"` // Maps to 'request_id', 0 if not linked
+	Request             string  `json:"request,omitempty"`    // Text of the linked request (joined from marys_requests)
+
+	// Joined from events, only set when listing all shrines
+	EventName string `json:"event_name,omitempty"`
+	EventSlug string `json:"event_slug,omitempty"`
+}
+
+// MapURL returns an OpenStreetMap link centered on the shrine, or "" if
+// its coordinates are unknown.
+func (s *Shrine) MapURL() string {
+	if !s.HasCoordinates {
+		return ""
+	}
+	return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%[1]f&mlon=%[2]f#map=16/%[1]f/%[2]f", s.Latitude, s.Longitude)
+}
@@ -0,0 +1,13 @@
+package model
+
+// Source is an external reference (book, article, web page) backing an
+// event. Maps to the 'external_sources' table.
+type Source struct {
+	ID         int    `json:"id"`
+	EventID    int    `json:"event_id"`
+	URL        string `json:"url"` // Maps to 'source_url' column
+	Title      string `json:"title"`
+	Author     string `json:"author,omitempty"`
+	Publisher  string `json:"publisher,omitempty"`
+	AccessedOn string `json:"accessed_on,omitempty"` // ISO 8601 date (YYYY-MM-DD), may be empty
+}
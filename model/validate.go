@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FieldError is a problem with the value of a field, named as in JSON.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationError lists the problems of a record. The editor refuses to
+// save records with problems, and the lint command reports them on the
+// data already saved (e.g. by the Django admin).
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) add(field, format string, args ...any) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ReservedSlugs are the first path segments of the app's own pages, which
+// an event's slug would be shadowed by.
+var ReservedSlugs = []string{"admin", "api", "compare", "export", "index", "shrines", "static", "stats", "timeline"}
+
+var slugPattern = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+
+// Validate checks the event's own fields, not its requests, blocks, etc.
+func (e *Event) Validate() ValidationError {
+	var errs ValidationError
+	if strings.TrimSpace(e.Name) == "" {
+		errs.add("name", "empty name")
+	}
+	switch {
+	case e.SlugDB == "":
+		errs.add("slug", "empty slug")
+	case !slugPattern.MatchString(e.SlugDB):
+		errs.add("slug", "slug %q can only have letters, digits and single dashes", e.SlugDB)
+	case slices.Contains(ReservedSlugs, strings.ToLower(e.SlugDB)):
+		errs.add("slug", "slug %q is taken by the /%s page", e.SlugDB, strings.ToLower(e.SlugDB))
+	}
+	if _, err := e.ParseYears(); err != nil {
+		errs.add("years", "years %q: %v", e.Years, err)
+	}
+	return errs
+}
+
+// Validate checks a block. Blocks saved without a language are in English.
+func (b *EventBlock) Validate() ValidationError {
+	var errs ValidationError
+	if len(b.Language) > 10 {
+		errs.add("language", "language %q is longer than 10 characters", b.Language)
+	}
+	if !IsKnownAuthorityPosition(b.AuthorityPosition) {
+		errs.add("authority_position", "unknown authority position %q", b.AuthorityPosition)
+	}
+	if len(b.ChurchAuthority) > 100 {
+		errs.add("church_authority", "church authority is longer than 100 characters")
+	}
+	return errs
+}
+
+func (r *Request) Validate() ValidationError {
+	var errs ValidationError
+	if strings.TrimSpace(r.Request) == "" {
+		errs.add("request", "empty request")
+	}
+	return errs
+}
+
+func (s *Source) Validate() ValidationError {
+	var errs ValidationError
+	if s.URL == "" && strings.TrimSpace(s.Title) == "" {
+		errs.add("url", "a source needs a URL or a title")
+	}
+	if s.URL != "" {
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("url", "url %q is not an absolute http(s) URL", s.URL)
+		}
+	}
+	if s.AccessedOn != "" {
+		if _, err := time.Parse(time.DateOnly, s.AccessedOn); err != nil {
+			errs.add("accessed_on", "accessed on %q is not a YYYY-MM-DD date", s.AccessedOn)
+		}
+	}
+	return errs
+}
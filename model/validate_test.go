@@ -0,0 +1,39 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventValidate(t *testing.T) {
+	tests := []struct {
+		event  Event
+		fields []string
+	}{
+		{Event{Name: "Our Lady of Akita", SlugDB: "our-lady-of-akita", Years: "1973-1981"}, nil},
+		{Event{Name: " ", SlugDB: "akita", Years: "1973"}, []string{"name"}},
+		{Event{Name: "Akita", SlugDB: "akita.json", Years: "1973"}, []string{"slug"}},
+		{Event{Name: "Akita", SlugDB: "our_lady", Years: "1973"}, []string{"slug"}},
+		{Event{Name: "Stats", SlugDB: "Stats", Years: "1973"}, []string{"slug"}},
+		{Event{Name: "Akita", Years: "soon"}, []string{"slug", "years"}},
+	}
+	for _, tt := range tests {
+		var fields []string
+		for _, fe := range tt.event.Validate() {
+			fields = append(fields, fe.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("%+v: invalid fields %v, want %v", tt.event, fields, tt.fields)
+		}
+	}
+}
+
+func TestSourceValidate(t *testing.T) {
+	if errs := (&Source{Title: "Akita: The Tears and Message of Mary", AccessedOn: "2024-05-13"}).Validate(); errs != nil {
+		t.Errorf("valid source: %v", errs)
+	}
+	errs := (&Source{URL: "/wiki/Akita", AccessedOn: "13/05/2024"}).Validate()
+	if len(errs) != 2 || errs[0].Field != "url" || errs[1].Field != "accessed_on" {
+		t.Errorf("invalid source: %v, want url and accessed_on errors", errs)
+	}
+}
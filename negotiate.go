@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// wantsJSON reports whether the client asked for JSON rather than HTML,
+// either with a .json suffix (see handleIndexOrView and handleView) or by
+// preferring application/json over text/html in its Accept header.
+func wantsJSON(r *http.Request) bool {
+	if strings.HasSuffix(r.URL.Path, ".json") {
+		return true
+	}
+	return acceptQuality(r, "application/json") > acceptQuality(r, "text/html")
+}
+
+// acceptQuality returns the q value the Accept header gives mediaType, from
+// its most specific match. It is 0 when the header doesn't accept it.
+func acceptQuality(r *http.Request, mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+	best, specificity := 0.0, -1
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		var s int
+		switch mt {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s < specificity {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		best, specificity = q, s
+	}
+	return best
+}
+
+// render writes data as JSON when the client asked for it, or executes the
+// template otherwise. Pages rendered this way double as API endpoints.
+func render(w http.ResponseWriter, r *http.Request, name string, data any) {
+	w.Header().Add("Vary", "Accept")
+	if !wantsJSON(r) {
+		renderTemplate(w, r, name, data)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, data)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write JSON", "path", r.URL.Path, "error", err)
+	}
+}
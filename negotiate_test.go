@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWantsJSON(t *testing.T) {
+	tests := []struct {
+		path, accept string
+		want         bool
+	}{
+		{"/", "", false},
+		{"/", "text/html,application/xhtml+xml,*/*;q=0.8", false},
+		{"/", "application/json", true},
+		{"/", "application/json, */*;q=0.1", true},
+		{"/", "text/html;q=0.5, application/json", true},
+		{"/", "text/html, application/json;q=0.9", false},
+		{"/", "*/*", false},
+		{"/fatima.json", "", true},
+		{"/index.json", "text/html", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.path, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		if got := wantsJSON(r); got != tt.want {
+			t.Errorf("wantsJSON(%s, %q) = %v, want %v", tt.path, tt.accept, got, tt.want)
+		}
+	}
+}
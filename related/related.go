@@ -0,0 +1,269 @@
+// Package related recommends the apparitions most similar to an event, with
+// the reasons they were picked ("also in France", "same decade").
+package related
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"marianapparitions/model"
+)
+
+// Match is an event similar to the one recommendations are made for.
+type Match struct {
+	Event   *model.Event
+	Score   float64
+	Reasons []string
+}
+
+// Weights of each kind of similarity. Years only count once: overlapping
+// years, or else the same decade, or else the same century.
+const (
+	countryWeight  = 3
+	overlapWeight  = 3
+	decadeWeight   = 2
+	centuryWeight  = 1
+	categoryWeight = 1.5
+	churchWeight   = 2 // per church that approved both
+	textWeight     = 6 // times the cosine similarity of their texts
+)
+
+// MinScore is the score below which events aren't recommended, so that
+// sharing a common category isn't enough.
+const MinScore = 2
+
+// minTextSimilarity is the cosine similarity from which texts are similar.
+const minTextSimilarity = 0.05
+
+// sharedTerms is the number of shared words given as a reason.
+const sharedTerms = 3
+
+// textLanguage is the language of the blocks compared, that of the stop
+// words and of the reasons.
+const textLanguage = "en"
+
+// Index holds the dataset with the word weights of each event, computed once.
+type Index struct {
+	events []model.Event
+	docs   []document
+}
+
+// document is the TF-IDF vector of an event's requests and blocks.
+type document struct {
+	weights map[string]float64
+	norm    float64
+}
+
+// NewIndex indexes events, which must have their Blocks and Requests loaded.
+func NewIndex(events []model.Event) *Index {
+	terms := make([]map[string]float64, len(events))
+	df := make(map[string]int)
+	for i := range events {
+		terms[i] = termFrequencies(&events[i])
+		for t := range terms[i] {
+			df[t]++
+		}
+	}
+
+	ix := &Index{events: events, docs: make([]document, len(events))}
+	n := float64(len(events))
+	for i, tf := range terms {
+		doc := document{weights: make(map[string]float64)}
+		for t, f := range tf {
+			// Words of a single event can't be shared, and words of every
+			// event don't tell them apart
+			if df[t] < 2 || df[t] == len(events) {
+				continue
+			}
+			w := (1 + math.Log(f)) * math.Log(n/float64(df[t]))
+			doc.weights[t] = w
+			doc.norm += w * w
+		}
+		doc.norm = math.Sqrt(doc.norm)
+		ix.docs[i] = doc
+	}
+	return ix
+}
+
+// Related returns at most limit events similar to the one with eventID,
+// best first. It is empty when the event isn't in the index.
+func (ix *Index) Related(eventID, limit int) []Match {
+	i := slices.IndexFunc(ix.events, func(e model.Event) bool { return e.ID == eventID })
+	if i < 0 {
+		return nil
+	}
+
+	var matches []Match
+	for j := range ix.events {
+		if j == i {
+			continue
+		}
+		m := ix.compare(i, j)
+		if m.Score >= MinScore {
+			matches = append(matches, m)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Event.Name, b.Event.Name))
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// compare scores how similar the j-th event is to the i-th one.
+func (ix *Index) compare(i, j int) Match {
+	a, b := &ix.events[i], &ix.events[j]
+	m := Match{Event: b}
+	add := func(weight float64, reason string) {
+		m.Score += weight
+		m.Reasons = append(m.Reasons, reason)
+	}
+
+	if a.Country != "" && strings.EqualFold(a.Country, b.Country) {
+		add(countryWeight, "also in "+b.Country)
+	}
+
+	if overlaps(a, b) {
+		add(overlapWeight, "overlapping years")
+	} else if ya, yb := a.FirstYear(), b.FirstYear(); ya > 0 && yb > 0 {
+		if ya/10 == yb/10 {
+			add(decadeWeight, "same decade")
+		} else if century := model.Century(ya); century == model.Century(yb) {
+			add(centuryWeight, "also in the "+model.CenturyName(century))
+		}
+	}
+
+	if a.Category != "" && strings.EqualFold(a.Category, b.Category) {
+		add(categoryWeight, fmt.Sprintf("same category (%s)", b.Category))
+	}
+
+	for _, church := range model.Churches {
+		if approvedBy(a, church) && approvedBy(b, church) {
+			add(churchWeight, fmt.Sprintf("also approved by the %s Church", church))
+		}
+	}
+
+	if similarity, shared := ix.similarity(i, j); similarity >= minTextSimilarity {
+		reason := "similar accounts"
+		if len(shared) > 0 {
+			reason = "both mention " + joinWords(shared)
+		}
+		add(textWeight*similarity, reason)
+	}
+	return m
+}
+
+// overlaps reports whether the years of a and b overlap, see
+// model.Event.MatchesYears.
+func overlaps(a, b *model.Event) bool {
+	ranges, _ := a.ParseYears()
+	for _, r := range ranges {
+		end := r.End
+		if r.Ongoing {
+			end = 0
+		}
+		if r.Start > 0 && b.MatchesYears(r.Start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+func approvedBy(e *model.Event, church string) bool {
+	verdict := e.Verdict(church)
+	return verdict != nil && verdict.IsApproval()
+}
+
+// similarity returns the cosine similarity of the i-th and j-th events'
+// texts, and the words weighing the most in it.
+func (ix *Index) similarity(i, j int) (float64, []string) {
+	a, b := ix.docs[i], ix.docs[j]
+	if a.norm == 0 || b.norm == 0 {
+		return 0, nil
+	}
+
+	type term struct {
+		word   string
+		weight float64
+	}
+	var shared []term
+	var dot float64
+	for t, wa := range a.weights {
+		if wb, ok := b.weights[t]; ok {
+			dot += wa * wb
+			shared = append(shared, term{t, wa * wb})
+		}
+	}
+	slices.SortFunc(shared, func(x, y term) int {
+		return cmp.Or(cmp.Compare(y.weight, x.weight), cmp.Compare(x.word, y.word))
+	})
+
+	var words []string
+	for _, t := range shared[:min(len(shared), sharedTerms)] {
+		words = append(words, t.word)
+	}
+	return dot / (a.norm * b.norm), words
+}
+
+// termFrequencies counts the words of the event's requests and blocks in
+// textLanguage (blocks without a language are in it). Requests count
+// double, being what apparitions are most often compared on.
+func termFrequencies(e *model.Event) map[string]float64 {
+	tf := make(map[string]float64)
+	for _, r := range e.Requests {
+		for _, w := range words(r.Request) {
+			tf[w] += 2
+		}
+	}
+	for _, b := range e.Blocks {
+		if b.Language != "" && b.Language != textLanguage {
+			continue
+		}
+		for _, w := range words(b.Content) {
+			tf[w]++
+		}
+	}
+	return tf
+}
+
+// words splits text into lowercase words, leaving out short and common ones.
+func words(text string) []string {
+	var ws []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(w)) >= 4 && !stopWords[w] {
+			ws = append(ws, w)
+		}
+	}
+	return ws
+}
+
+// joinWords returns e.g. "rosary, penance and chapel".
+func joinWords(ws []string) string {
+	if len(ws) == 1 {
+		return ws[0]
+	}
+	return strings.Join(ws[:len(ws)-1], ", ") + " and " + ws[len(ws)-1]
+}
+
+// stopWords are frequent words of 4 letters or more that say nothing about
+// an apparition. Words common to most events are also discounted by NewIndex.
+var stopWords = map[string]bool{
+	"about": true, "after": true, "also": true, "asked": true, "been": true,
+	"before": true, "being": true, "came": true, "come": true, "could": true,
+	"does": true, "during": true, "each": true, "from": true, "have": true,
+	"having": true, "here": true, "into": true, "more": true, "most": true,
+	"much": true, "must": true, "only": true, "other": true, "over": true,
+	"said": true, "same": true, "should": true, "some": true, "such": true,
+	"than": true, "that": true, "their": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "those": true,
+	"through": true, "told": true, "under": true, "upon": true, "very": true,
+	"were": true, "what": true, "when": true, "where": true, "which": true,
+	"while": true, "will": true, "with": true, "would": true, "year": true,
+	"years": true, "your": true,
+}
@@ -0,0 +1,62 @@
+package related
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"marianapparitions/model"
+)
+
+func TestRelated(t *testing.T) {
+	approved := []model.EventBlock{{ChurchAuthority: "Catholic Church", AuthorityPosition: "approved", Content: "The children saw a lady of light."}}
+	events := []model.Event{
+		{ID: 1, Name: "Lourdes", Category: "Apparition", Country: "France", Years: "1858", Blocks: approved,
+			Requests: []model.Request{{Request: "Build a chapel and come in procession"}, {Request: "Penance, penance, penance"}}},
+		{ID: 2, Name: "Pontmain", Category: "Apparition", Country: "France", Years: "1871", Blocks: approved,
+			Requests: []model.Request{{Request: "Pray, my children"}}},
+		{ID: 3, Name: "La Salette", Category: "Apparition", Country: "France", Years: "1846", Blocks: approved,
+			Requests: []model.Request{{Request: "Do penance and come in procession"}}},
+		{ID: 4, Name: "Akita", Category: "Locution", Country: "Japan", Years: "1973-1975", Requests: []model.Request{{Request: "Pray the rosary"}}},
+		{ID: 5, Name: "Guadalupe", Category: "Apparition", Country: "Mexico", Years: "1531",
+			Requests: []model.Request{{Request: "Build a chapel on this hill"}}},
+	}
+
+	matches := NewIndex(events).Related(1, 3)
+
+	var names []string
+	for _, m := range matches {
+		names = append(names, m.Event.Name)
+	}
+	if !slices.Equal(names, []string{"La Salette", "Pontmain", "Guadalupe"}) {
+		t.Fatalf("Related(Lourdes) = %v, want La Salette, Pontmain then Guadalupe", names)
+	}
+	for _, want := range []string{"also in France", "also in the 19th century", "also approved by the Catholic Church", "both mention penance, procession and children"} {
+		if !slices.Contains(matches[0].Reasons, want) {
+			t.Errorf("La Salette reasons = %q, want %q among them", matches[0].Reasons, want)
+		}
+	}
+	if len(NewIndex(events).Related(42, 3)) != 0 {
+		t.Error("Related of an unknown event isn't empty")
+	}
+}
+
+func TestRelatedIgnoresOtherLanguages(t *testing.T) {
+	french := []model.EventBlock{{Language: "fr", Content: "Elle parlait avec les enfants dans la grotte, pour leur demander une chapelle."}}
+	events := []model.Event{
+		{ID: 1, Name: "Lourdes", Category: "Apparition", Country: "France", Years: "1858", Blocks: french},
+		{ID: 2, Name: "Pontmain", Category: "Apparition", Country: "France", Years: "1871", Blocks: french},
+		{ID: 3, Name: "Akita", Category: "Locution", Country: "Japan", Years: "1973-1975",
+			Blocks: []model.EventBlock{{Language: "en", Content: "The statue wept in the convent chapel."}}},
+	}
+
+	matches := NewIndex(events).Related(1, 3)
+	if len(matches) != 1 {
+		t.Fatalf("Related(Lourdes) = %v, want Pontmain", matches)
+	}
+	for _, reason := range matches[0].Reasons {
+		if strings.HasPrefix(reason, "both mention") {
+			t.Errorf("reason %q compares French text with English stop words", reason)
+		}
+	}
+}
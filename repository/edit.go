@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"marianapparitions/model"
+)
+
+// The functions below save what the editor (admin pages and API) changes.
+// Records are validated first: a model.ValidationError is returned for
+// invalid ones, and sql.ErrNoRows when what is updated or deleted doesn't
+// exist (or belongs to another event).
+
+// CreateEventContext inserts e and sets its ID.
+func CreateEventContext(ctx context.Context, db *sql.DB, e *model.Event) error {
+	if err := validateEvent(ctx, db, e); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx,
+		`INSERT INTO events (slug, name, category, years, country, description, wikipedia_section_title, image_filename) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.SlugDB, e.Name, e.Category, e.Years, e.Country, e.Description, e.WikipediaSectionTitle, nullIfEmpty(e.ImageFilename))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	e.ID = int(id)
+	return err
+}
+
+// UpdateEventContext saves the event's own fields. A changed slug is
+// recorded in slug_history, so the old URL redirects.
+func UpdateEventContext(ctx context.Context, db *sql.DB, e *model.Event) error {
+	if err := validateEvent(ctx, db, e); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx,
+		`UPDATE events SET slug = ?, name = ?, category = ?, years = ?, country = ?, description = ?, wikipedia_section_title = ?, image_filename = ? WHERE id = ?`,
+		e.SlugDB, e.Name, e.Category, e.Years, e.Country, e.Description, e.WikipediaSectionTitle, nullIfEmpty(e.ImageFilename), e.ID)
+	return checkAffected(res, err)
+}
+
+// DeleteEventContext deletes the event and everything attached to it.
+func DeleteEventContext(ctx context.Context, db *sql.DB, id int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Shrines first, they may reference marys_requests
+	for _, table := range []string{"shrines", "event_blocks", "marys_requests", "external_sources", "media", "slug_history"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE event_id = ?", id); err != nil {
+			return err
+		}
+	}
+	res, err := tx.ExecContext(ctx, "DELETE FROM events WHERE id = ?", id)
+	if err := checkAffected(res, err); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// validateEvent adds the uniqueness of the slug, case-insensitive like the
+// URLs, to the event's own rules.
+func validateEvent(ctx context.Context, db *sql.DB, e *model.Event) error {
+	errs := e.Validate()
+	if e.SlugDB != "" {
+		var name string
+		err := db.QueryRowContext(ctx, `SELECT COALESCE(name, '') FROM events WHERE slug = ? COLLATE NOCASE AND id <> ?`, e.SlugDB, e.ID).Scan(&name)
+		if err == nil {
+			errs = append(errs, model.FieldError{Field: "slug", Message: fmt.Sprintf("slug %q is already used by %s", e.SlugDB, name)})
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// CreateBlockContext inserts b after the other blocks of its event and sets
+// its ID and Ordering.
+func CreateBlockContext(ctx context.Context, db *sql.DB, b *model.EventBlock) error {
+	if errs := b.Validate(); len(errs) > 0 {
+		return errs
+	}
+	if b.Language == "" {
+		b.Language = "en"
+	}
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(ordering) + 1, 0) FROM event_blocks WHERE event_id = ?`, b.EventID).Scan(&b.Ordering); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx,
+		`INSERT INTO event_blocks (event_id, language, title, content, ordering, church_authority, authority_position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		b.EventID, b.Language, b.Title, b.Content, b.Ordering, nullIfEmpty(b.ChurchAuthority), nullIfEmpty(b.AuthorityPosition))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	b.ID = int(id)
+	return err
+}
+
+// UpdateBlockContext saves b, Ordering included, and bumps its updated_at.
+func UpdateBlockContext(ctx context.Context, db *sql.DB, b *model.EventBlock) error {
+	if errs := b.Validate(); len(errs) > 0 {
+		return errs
+	}
+	if b.Language == "" {
+		b.Language = "en"
+	}
+	res, err := db.ExecContext(ctx,
+		`UPDATE event_blocks SET language = ?, title = ?, content = ?, ordering = ?, church_authority = ?, authority_position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND event_id = ?`,
+		b.Language, b.Title, b.Content, b.Ordering, nullIfEmpty(b.ChurchAuthority), nullIfEmpty(b.AuthorityPosition), b.ID, b.EventID)
+	return checkAffected(res, err)
+}
+
+func DeleteBlockContext(ctx context.Context, db *sql.DB, eventID, id int) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM event_blocks WHERE id = ? AND event_id = ?`, id, eventID)
+	return checkAffected(res, err)
+}
+
+// ReorderBlocksContext sets the Ordering of the event's blocks to their
+// index in blockIDs, which must list each of them once.
+func ReorderBlocksContext(ctx context.Context, db *sql.DB, eventID int, blockIDs []int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM event_blocks WHERE event_id = ?`, eventID)
+	if err != nil {
+		return err
+	}
+	var current []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		current = append(current, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sorted := slices.Sorted(slices.Values(blockIDs))
+	slices.Sort(current)
+	if !slices.Equal(sorted, current) {
+		return model.ValidationError{{Field: "order", Message: fmt.Sprintf("the order must list the event's blocks %v once each", current)}}
+	}
+
+	for i, id := range blockIDs {
+		if _, err := tx.ExecContext(ctx, `UPDATE event_blocks SET ordering = ? WHERE id = ?`, i, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CreateRequestContext inserts r and sets its ID.
+func CreateRequestContext(ctx context.Context, db *sql.DB, r *model.Request) error {
+	if errs := r.Validate(); len(errs) > 0 {
+		return errs
+	}
+	res, err := db.ExecContext(ctx, `INSERT INTO marys_requests (event_id, request) VALUES (?, ?)`, r.EventID, r.Request)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	r.ID = int(id)
+	return err
+}
+
+func UpdateRequestContext(ctx context.Context, db *sql.DB, r *model.Request) error {
+	if errs := r.Validate(); len(errs) > 0 {
+		return errs
+	}
+	res, err := db.ExecContext(ctx, `UPDATE marys_requests SET request = ? WHERE id = ? AND event_id = ?`, r.Request, r.ID, r.EventID)
+	return checkAffected(res, err)
+}
+
+// DeleteRequestContext deletes the request. Shrines built at that request
+// are kept, without it.
+func DeleteRequestContext(ctx context.Context, db *sql.DB, eventID, id int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM marys_requests WHERE id = ? AND event_id = ?`, id, eventID)
+	if err := checkAffected(res, err); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE shrines SET request_id = NULL WHERE request_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateSourceContext inserts s and sets its ID.
+func CreateSourceContext(ctx context.Context, db *sql.DB, s *model.Source) error {
+	if errs := s.Validate(); len(errs) > 0 {
+		return errs
+	}
+	res, err := db.ExecContext(ctx,
+		`INSERT INTO external_sources (event_id, source_url, title, author, publisher, accessed_on) VALUES (?, ?, ?, ?, ?, ?)`,
+		s.EventID, nullIfEmpty(s.URL), nullIfEmpty(s.Title), nullIfEmpty(s.Author), nullIfEmpty(s.Publisher), nullIfEmpty(s.AccessedOn))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	s.ID = int(id)
+	return err
+}
+
+func UpdateSourceContext(ctx context.Context, db *sql.DB, s *model.Source) error {
+	if errs := s.Validate(); len(errs) > 0 {
+		return errs
+	}
+	res, err := db.ExecContext(ctx,
+		`UPDATE external_sources SET source_url = ?, title = ?, author = ?, publisher = ?, accessed_on = ? WHERE id = ? AND event_id = ?`,
+		nullIfEmpty(s.URL), nullIfEmpty(s.Title), nullIfEmpty(s.Author), nullIfEmpty(s.Publisher), nullIfEmpty(s.AccessedOn), s.ID, s.EventID)
+	return checkAffected(res, err)
+}
+
+func DeleteSourceContext(ctx context.Context, db *sql.DB, eventID, id int) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM external_sources WHERE id = ? AND event_id = ?`, id, eventID)
+	return checkAffected(res, err)
+}
+
+// checkAffected turns the result of an UPDATE or DELETE that matched no
+// row into sql.ErrNoRows.
+func checkAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"marianapparitions/model"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openTestDB returns an empty database with the app's schema.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	schema, err := os.ReadFile("../schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func createTestEvent(t *testing.T, db *sql.DB, slug string) *model.Event {
+	t.Helper()
+	e := &model.Event{Name: "Our Lady of " + slug, SlugDB: slug, Category: "Apparition", Years: "1879"}
+	if err := CreateEventContext(context.Background(), db, e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func createTestBlock(t *testing.T, db *sql.DB, eventID int) *model.EventBlock {
+	t.Helper()
+	b := &model.EventBlock{EventID: eventID, Title: "Apparition", Content: "The villagers saw a lady of light."}
+	if err := CreateBlockContext(context.Background(), db, b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func count(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestReorderBlocks(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	knock := createTestEvent(t, db, "knock")
+	b1, b2 := createTestBlock(t, db, knock.ID), createTestBlock(t, db, knock.ID)
+	foreign := createTestBlock(t, db, createTestEvent(t, db, "lourdes").ID)
+
+	for name, ids := range map[string][]int{
+		"missing":   {b2.ID},
+		"duplicate": {b2.ID, b2.ID},
+		"foreign":   {b2.ID, foreign.ID},
+	} {
+		var invalid model.ValidationError
+		if err := ReorderBlocksContext(ctx, db, knock.ID, ids); !errors.As(err, &invalid) {
+			t.Errorf("%s block: err = %v, want a ValidationError", name, err)
+		}
+	}
+
+	if err := ReorderBlocksContext(ctx, db, knock.ID, []int{b2.ID, b1.ID}); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := GetBlocksByEventIDContext(ctx, db, knock.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0].ID != b2.ID || blocks[1].ID != b1.ID {
+		t.Errorf("blocks = %+v, want #%d then #%d", blocks, b2.ID, b1.ID)
+	}
+}
+
+func TestValidateEventSlug(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	knock := createTestEvent(t, db, "knock")
+
+	var invalid model.ValidationError
+	err := CreateEventContext(ctx, db, &model.Event{Name: "Knock again", SlugDB: "Knock", Years: "1879"})
+	if !errors.As(err, &invalid) || invalid[0].Field != "slug" {
+		t.Errorf("case-variant duplicate slug: err = %v, want a slug error", err)
+	}
+
+	// The event's own slug isn't a duplicate
+	knock.SlugDB = "Knock"
+	if err := UpdateEventContext(ctx, db, knock); err != nil {
+		t.Errorf("changing the case of the event's own slug: %v", err)
+	}
+}
+
+func TestDeleteEvent(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	knock := createTestEvent(t, db, "knock")
+	lourdes := createTestEvent(t, db, "lourdes")
+	createTestBlock(t, db, knock.ID)
+	createTestBlock(t, db, lourdes.ID)
+	req := &model.Request{EventID: knock.ID, Request: "Build a chapel here"}
+	if err := CreateRequestContext(ctx, db, req); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateSourceContext(ctx, db, &model.Source{EventID: knock.ID, Title: "Knock Shrine"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO shrines (event_id, name, request_id) VALUES (?, 'Knock Shrine', ?)`, knock.ID, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO media (event_id, source) VALUES (?, 'knock.jpg')`, knock.ID); err != nil {
+		t.Fatal(err)
+	}
+	knock.SlugDB = "our-lady-of-knock" // Leaves "knock" in slug_history
+	if err := UpdateEventContext(ctx, db, knock); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM slug_history WHERE event_id = ?", knock.ID); n != 1 {
+		t.Fatalf("%d slug_history rows before the delete, want 1", n)
+	}
+
+	if err := DeleteEventContext(ctx, db, knock.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM events WHERE id = ?", knock.ID); n != 0 {
+		t.Errorf("the event is still there")
+	}
+	for _, table := range []string{"shrines", "event_blocks", "marys_requests", "external_sources", "media", "slug_history"} {
+		if n := count(t, db, "SELECT COUNT(*) FROM "+table+" WHERE event_id = ?", knock.ID); n != 0 {
+			t.Errorf("%d rows of %s left", n, table)
+		}
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM event_blocks WHERE event_id = ?", lourdes.ID); n != 1 {
+		t.Errorf("%d blocks left to the other event, want 1", n)
+	}
+	if err := DeleteEventContext(ctx, db, knock.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("deleting again: err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestDeleteRequest(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	knock := createTestEvent(t, db, "knock")
+	req := &model.Request{EventID: knock.ID, Request: "Build a chapel here"}
+	if err := CreateRequestContext(ctx, db, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO shrines (event_id, name, built_at_marys_request, request_id) VALUES (?, 'Knock Shrine', 1, ?)`, knock.ID, req.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteRequestContext(ctx, db, knock.ID, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM marys_requests"); n != 0 {
+		t.Errorf("%d requests left, want 0", n)
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM shrines WHERE request_id IS NULL"); n != 1 {
+		t.Errorf("%d shrines without a request, want the shrine kept with a NULL request_id", n)
+	}
+}
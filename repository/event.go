@@ -5,34 +5,18 @@ import (
 	"database/sql"
 
 	"marianapparitions/model"
-
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
 )
 
-var tracer = otel.Tracer("marianapparitions/repository")
-
-const dbSystem = "sqlite"
-
 func GetRequestsByEventID(db *sql.DB, eventID int) ([]model.Request, error) {
 	return GetRequestsByEventIDContext(context.Background(), db, eventID)
 }
 
 func GetRequestsByEventIDContext(ctx context.Context, db *sql.DB, eventID int) ([]model.Request, error) {
-	const query = `SELECT id, event_id, request FROM marys_requests WHERE event_id = ?`
-	ctx, span := tracer.Start(ctx, "GetRequestsByEventID")
-	defer span.End()
-	span.SetAttributes(
-		attribute.String("db.system", dbSystem),
-		attribute.String("db.statement", query),
-	)
+	const query = `SELECT id, event_id, request FROM marys_requests WHERE event_id = ? ORDER BY id`
 
 	var requests []model.Request
 	rows, err := db.QueryContext(ctx, query, eventID)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -40,14 +24,12 @@ func GetRequestsByEventIDContext(ctx context.Context, db *sql.DB, eventID int) (
 	for rows.Next() {
 		var r model.Request
 		if err := rows.Scan(&r.ID, &r.EventID, &r.Request); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
 			return nil, err
 		}
 		requests = append(requests, r)
 	}
 
-	return requests, nil
+	return requests, rows.Err()
 }
 
 func GetBlocksByEventID(db *sql.DB, eventID int) ([]model.EventBlock, error) {
@@ -55,34 +37,26 @@ func GetBlocksByEventID(db *sql.DB, eventID int) ([]model.EventBlock, error) {
 }
 
 func GetBlocksByEventIDContext(ctx context.Context, db *sql.DB, eventID int) ([]model.EventBlock, error) {
-	const query = `SELECT id, title, content, event_id, ordering, COALESCE(church_authority, ''), COALESCE(authority_position, '') FROM event_blocks WHERE event_id = ? ORDER BY ordering`
-	ctx, span := tracer.Start(ctx, "GetBlocksByEventID")
-	defer span.End()
-	span.SetAttributes(
-		attribute.String("db.system", dbSystem),
-		attribute.String("db.statement", query),
-	)
+	const query = `SELECT id, title, content, event_id, ordering, COALESCE(church_authority, ''), COALESCE(authority_position, ''), COALESCE(language, 'en'), updated_at FROM event_blocks WHERE event_id = ? ORDER BY ordering, id`
 
 	var blocks []model.EventBlock
 	rows, err := db.QueryContext(ctx, query, eventID)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return blocks, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var r model.EventBlock
-		if err := rows.Scan(&r.ID, &r.Title, &r.Content, &r.EventID, &r.Ordering, &r.ChurchAuthority, &r.AuthorityPosition); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&r.ID, &r.Title, &r.Content, &r.EventID, &r.Ordering, &r.ChurchAuthority, &r.AuthorityPosition, &r.Language, &updatedAt); err != nil {
 			return nil, err
 		}
+		r.UpdatedAt = updatedAt.Time
 		blocks = append(blocks, r)
 	}
 
-	return blocks, nil
+	return blocks, rows.Err()
 }
 
 func GetEventBySlug(db *sql.DB, slug string) (model.Event, error) {
@@ -90,27 +64,39 @@ func GetEventBySlug(db *sql.DB, slug string) (model.Event, error) {
 }
 
 func GetEventBySlugContext(ctx context.Context, db *sql.DB, slug string) (model.Event, error) {
-	const query = `SELECT e.id, e.category, e.name, e.wikipedia_section_title, COALESCE(e.image_filename, '') AS image_filename, e.years, COALESCE(e.slug, '') as slug, COALESCE(e.country, '') as country FROM events AS e WHERE e.slug = ?`
-	ctx, span := tracer.Start(ctx, "GetEventBySlug")
-	defer span.End()
-	span.SetAttributes(
-		attribute.String("db.system", dbSystem),
-		attribute.String("db.statement", query),
-	)
+	return getEventContext(ctx, db, "e.slug = ?", slug)
+}
+
+func GetEventByIDContext(ctx context.Context, db *sql.DB, id int) (model.Event, error) {
+	return getEventContext(ctx, db, "e.id = ?", id)
+}
+
+// getEventContext loads the event matching where, with everything attached to it.
+func getEventContext(ctx context.Context, db *sql.DB, where string, arg any) (model.Event, error) {
+	query := `SELECT e.id, COALESCE(e.category, ''), COALESCE(e.name, ''), COALESCE(e.description, ''), COALESCE(e.wikipedia_section_title, ''), COALESCE(e.image_filename, '') AS image_filename, COALESCE(e.years, ''), COALESCE(e.slug, '') as slug, COALESCE(e.country, '') as country FROM events AS e WHERE ` + where
 
 	var e model.Event
-	row := db.QueryRowContext(ctx, query, slug)
-	err := row.Scan(&e.ID, &e.Category, &e.Name, &e.WikipediaSectionTitle, &e.ImageFilename, &e.Years, &e.SlugDB, &e.Country)
+	row := db.QueryRowContext(ctx, query, arg)
+	err := row.Scan(&e.ID, &e.Category, &e.Name, &e.Description, &e.WikipediaSectionTitle, &e.ImageFilename, &e.Years, &e.SlugDB, &e.Country)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
 		return e, err
 	}
 
-	e.Requests, _ = GetRequestsByEventIDContext(ctx, db, e.ID)
-	e.Blocks, _ = GetBlocksByEventIDContext(ctx, db, e.ID)
+	if e.Requests, err = GetRequestsByEventIDContext(ctx, db, e.ID); err != nil {
+		return e, err
+	}
+	if e.Blocks, err = GetBlocksByEventIDContext(ctx, db, e.ID); err != nil {
+		return e, err
+	}
+	if e.Sources, err = GetSourcesByEventIDContext(ctx, db, e.ID); err != nil {
+		return e, err
+	}
+	if e.Media, err = GetMediaByEventIDContext(ctx, db, e.ID); err != nil {
+		return e, err
+	}
+	if e.Shrines, err = GetShrinesByEventIDContext(ctx, db, e.ID); err != nil {
+		return e, err
+	}
 
 	return e, nil
 }
@@ -121,18 +107,10 @@ func GetAllEvents(db *sql.DB) ([]model.Event, error) {
 
 func GetAllEventsContext(ctx context.Context, db *sql.DB) ([]model.Event, error) {
 	const query = `SELECT id, category, name, description, wikipedia_section_title, COALESCE(image_filename, '') AS image_filename, years, COALESCE(slug, '') as slug, COALESCE(country, '') as country FROM events ORDER BY CAST(years AS INTEGER) DESC`
-	ctx, span := tracer.Start(ctx, "GetAllEvents")
-	defer span.End()
-	span.SetAttributes(
-		attribute.String("db.system", dbSystem),
-		attribute.String("db.statement", query),
-	)
 
 	var events []model.Event
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -140,8 +118,6 @@ func GetAllEventsContext(ctx context.Context, db *sql.DB) ([]model.Event, error)
 	for rows.Next() {
 		var e model.Event
 		if err := rows.Scan(&e.ID, &e.Category, &e.Name, &e.Description, &e.WikipediaSectionTitle, &e.ImageFilename, &e.Years, &e.SlugDB, &e.Country); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
 			return nil, err
 		}
 		var blockErr error
@@ -149,7 +125,39 @@ func GetAllEventsContext(ctx context.Context, db *sql.DB) ([]model.Event, error)
 		if blockErr != nil {
 			panic(blockErr)
 		}
+		// Needed for the request counts (exports, sorting)
+		e.Requests, err = GetRequestsByEventIDContext(ctx, db, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		// Needed for the index thumbnails
+		e.Media, err = GetMediaByEventIDContext(ctx, db, e.ID)
+		if err != nil {
+			return nil, err
+		}
 		events = append(events, e)
 	}
 	return events, nil
 }
+
+// GetOrphanRequestsContext returns the requests whose event_id doesn't match any event.
+func GetOrphanRequestsContext(ctx context.Context, db *sql.DB) ([]model.Request, error) {
+	const query = `SELECT r.id, COALESCE(r.event_id, 0), COALESCE(r.request, '') FROM marys_requests AS r LEFT JOIN events AS e ON e.id = r.event_id WHERE e.id IS NULL ORDER BY r.id`
+
+	var requests []model.Request
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.Request
+		if err := rows.Scan(&r.ID, &r.EventID, &r.Request); err != nil {
+			return nil, err
+		}
+		requests = append(requests, r)
+	}
+
+	return requests, rows.Err()
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"marianapparitions/model"
+)
+
+func GetMediaByEventID(db *sql.DB, eventID int) ([]model.Media, error) {
+	return GetMediaByEventIDContext(context.Background(), db, eventID)
+}
+
+func GetMediaByEventIDContext(ctx context.Context, db *sql.DB, eventID int) ([]model.Media, error) {
+	const query = `SELECT id, event_id, kind, source, COALESCE(caption, ''), COALESCE(credit, ''), COALESCE(license, ''), ordering FROM media WHERE event_id = ? ORDER BY ordering, id`
+
+	var media []model.Media
+	rows, err := db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m model.Media
+		if err := rows.Scan(&m.ID, &m.EventID, &m.Kind, &m.Source, &m.Caption, &m.Credit, &m.License, &m.Ordering); err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+
+	return media, rows.Err()
+}
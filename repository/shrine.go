@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"marianapparitions/model"
+)
+
+const shrineColumns = `s.id, s.event_id, s.name, COALESCE(s.website, ''), s.latitude, s.longitude, COALESCE(s.founded_year, 0), s.built_at_marys_request, COALESCE(s.request_id, 0), COALESCE(r.request, '')`
+
+func scanShrine(rows *sql.Rows, extra ...any) (model.Shrine, error) {
+	var s model.Shrine
+	var lat, lng sql.NullFloat64
+	dest := append([]any{&s.ID, &s.EventID, &s.Name, &s.Website, &lat, &lng, &s.FoundedYear, &s.BuiltAtMarysRequest, &s.RequestID, &s.Request}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return s, err
+	}
+	if lat.Valid && lng.Valid {
+		s.Latitude, s.Longitude, s.HasCoordinates = lat.Float64, lng.Float64, true
+	}
+	return s, nil
+}
+
+func GetShrinesByEventID(db *sql.DB, eventID int) ([]model.Shrine, error) {
+	return GetShrinesByEventIDContext(context.Background(), db, eventID)
+}
+
+func GetShrinesByEventIDContext(ctx context.Context, db *sql.DB, eventID int) ([]model.Shrine, error) {
+	const query = `SELECT ` + shrineColumns + ` FROM shrines AS s LEFT JOIN marys_requests AS r ON r.id = s.request_id WHERE s.event_id = ? ORDER BY s.founded_year, s.name`
+
+	var shrines []model.Shrine
+	rows, err := db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanShrine(rows)
+		if err != nil {
+			return nil, err
+		}
+		shrines = append(shrines, s)
+	}
+
+	return shrines, rows.Err()
+}
+
+func GetAllShrines(db *sql.DB) ([]model.Shrine, error) {
+	return GetAllShrinesContext(context.Background(), db)
+}
+
+func GetAllShrinesContext(ctx context.Context, db *sql.DB) ([]model.Shrine, error) {
+	const query = `SELECT ` + shrineColumns + `, e.name, COALESCE(e.slug, '') FROM shrines AS s JOIN events AS e ON e.id = s.event_id LEFT JOIN marys_requests AS r ON r.id = s.request_id ORDER BY s.name`
+
+	var shrines []model.Shrine
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventName, eventSlug string
+		s, err := scanShrine(rows, &eventName, &eventSlug)
+		if err != nil {
+			return nil, err
+		}
+		s.EventName, s.EventSlug = eventName, eventSlug
+		shrines = append(shrines, s)
+	}
+
+	return shrines, rows.Err()
+}
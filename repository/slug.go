@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// ResolveSlug returns the current slug of the event a URL slug refers to:
+// a case or trailing-slash variant of a current slug, or a slug the event
+// had before being renamed. It returns sql.ErrNoRows if there is no match.
+func ResolveSlug(db *sql.DB, slug string) (string, error) {
+	return ResolveSlugContext(context.Background(), db, slug)
+}
+
+func ResolveSlugContext(ctx context.Context, db *sql.DB, slug string) (string, error) {
+	// A current slug wins over a slug another event had before
+	const query = `SELECT e.slug, 0 AS priority FROM events AS e WHERE e.slug = ? COLLATE NOCASE
+		UNION ALL
+		SELECT e.slug, 1 AS priority FROM slug_history AS h JOIN events AS e ON e.id = h.event_id WHERE h.slug = ? COLLATE NOCASE
+		ORDER BY priority
+		LIMIT 1`
+
+	slug = strings.Trim(slug, "/")
+	var current string
+	var priority int
+	err := db.QueryRowContext(ctx, query, slug, slug).Scan(&current, &priority)
+	return current, err
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResolveSlug(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	knock := createTestEvent(t, db, "knock")
+	knock.SlugDB = "our-lady-of-knock" // "knock" now redirects to it
+	if err := UpdateEventContext(ctx, db, knock); err != nil {
+		t.Fatal(err)
+	}
+
+	for slug, want := range map[string]string{"Our-Lady-of-Knock/": "our-lady-of-knock", "KNOCK": "our-lady-of-knock"} {
+		if got, err := ResolveSlugContext(ctx, db, slug); err != nil || got != want {
+			t.Errorf("ResolveSlug(%q) = %q, %v, want %q", slug, got, err, want)
+		}
+	}
+
+	// A current slug wins over the same slug in another event's history
+	createTestEvent(t, db, "Knock")
+	if got, err := ResolveSlugContext(ctx, db, "knock"); err != nil || got != "Knock" {
+		t.Errorf("ResolveSlug(%q) = %q, %v, want the event whose slug it is now", "knock", got, err)
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"marianapparitions/model"
+)
+
+func GetSourcesByEventID(db *sql.DB, eventID int) ([]model.Source, error) {
+	return GetSourcesByEventIDContext(context.Background(), db, eventID)
+}
+
+func GetSourcesByEventIDContext(ctx context.Context, db *sql.DB, eventID int) ([]model.Source, error) {
+	const query = `SELECT id, event_id, COALESCE(source_url, ''), COALESCE(title, ''), COALESCE(author, ''), COALESCE(publisher, ''), COALESCE(accessed_on, '') FROM external_sources WHERE event_id = ? ORDER BY id`
+
+	var sources []model.Source
+	rows, err := db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s model.Source
+		if err := rows.Scan(&s.ID, &s.EventID, &s.URL, &s.Title, &s.Author, &s.Publisher, &s.AccessedOn); err != nil {
+			return nil, err
+		}
+		sources = append(sources, s)
+	}
+
+	return sources, rows.Err()
+}
+
+func GetAllSources(db *sql.DB) ([]model.Source, error) {
+	return GetAllSourcesContext(context.Background(), db)
+}
+
+func GetAllSourcesContext(ctx context.Context, db *sql.DB) ([]model.Source, error) {
+	const query = `SELECT id, event_id, COALESCE(source_url, ''), COALESCE(title, ''), COALESCE(author, ''), COALESCE(publisher, ''), COALESCE(accessed_on, '') FROM external_sources ORDER BY event_id, id`
+
+	var sources []model.Source
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s model.Source
+		if err := rows.Scan(&s.ID, &s.EventID, &s.URL, &s.Title, &s.Author, &s.Publisher, &s.AccessedOn); err != nil {
+			return nil, err
+		}
+		sources = append(sources, s)
+	}
+
+	return sources, rows.Err()
+}
@@ -1,4 +1,5 @@
-CREATE TABLE events (
+
+CREATE TABLE IF NOT EXISTS events (
     id INTEGER PRIMARY KEY,
     category TEXT,
     name TEXT,
@@ -9,3 +10,71 @@ CREATE TABLE events (
     slug TEXT,
     country TEXT
 );
+
+CREATE TABLE IF NOT EXISTS marys_requests (
+    id INTEGER PRIMARY KEY,
+    event_id INTEGER REFERENCES events(id),
+    request TEXT
+);
+
+CREATE TABLE IF NOT EXISTS event_blocks (
+    id INTEGER PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id),
+    language VARCHAR(10) NOT NULL DEFAULT 'en',
+    title TEXT,
+    content TEXT,
+    ordering INTEGER NOT NULL DEFAULT 0,
+    church_authority VARCHAR(100),
+    authority_position VARCHAR(50),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS external_sources (
+    id INTEGER PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id),
+    source_url TEXT,
+    title TEXT,
+    author TEXT,
+    publisher TEXT,
+    accessed_on TEXT -- ISO 8601 date, e.g. 2024-05-13
+);
+
+CREATE TABLE IF NOT EXISTS media (
+    id INTEGER PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id),
+    kind TEXT NOT NULL DEFAULT 'image', -- 'image', 'video' or 'audio'
+    source TEXT NOT NULL, -- file name under static/images/ or absolute URL
+    caption TEXT,
+    credit TEXT,
+    license TEXT,
+    ordering INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS shrines (
+    id INTEGER PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id),
+    name TEXT NOT NULL,
+    website TEXT,
+    latitude REAL,
+    longitude REAL,
+    founded_year INTEGER,
+    built_at_marys_request BOOLEAN NOT NULL DEFAULT 0,
+    request_id INTEGER REFERENCES marys_requests(id) -- the request that asked for it, if any
+);
+
+-- Previous slugs of renamed events, so old links can redirect to the current one
+CREATE TABLE IF NOT EXISTS slug_history (
+    slug TEXT PRIMARY KEY COLLATE NOCASE,
+    event_id INTEGER NOT NULL REFERENCES events(id),
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Record slug changes wherever they come from (this app, the Django admin, the sqlite3 shell)
+CREATE TRIGGER IF NOT EXISTS events_slug_history
+AFTER UPDATE OF slug ON events
+WHEN OLD.slug IS NOT NULL AND OLD.slug <> '' AND OLD.slug IS NOT NEW.slug
+BEGIN
+    INSERT OR REPLACE INTO slug_history (slug, event_id) VALUES (OLD.slug, OLD.id);
+    DELETE FROM slug_history WHERE slug = NEW.slug;
+END;
@@ -1,76 +1,118 @@
 package main
 
 import (
-	"sort"
-	"strconv"
+	"cmp"
+	"slices"
 	"strings"
+	"time"
+
+	"marianapparitions/viewmodel"
+
+	"golang.org/x/text/collate"
 )
 
 type Sortable interface {
 	GetName() string
 	GetCategory() string
 	GetYears() string
+	GetCountry() string
+	GetSlug() string
+	GetFirstYear() int
+	GetApprovalLevel() int
+	GetRequestCount() int
+	GetLastUpdated() time.Time
 }
 
-func applySorting[T Sortable](events []T, sortBy string) {
-	// Split sort_by into field and direction (e.g., "name_asc" -> ["name", "asc"])
-	parts := strings.Split(sortBy, "_")
-	if len(parts) != 2 {
-		return // Invalid format, skip sorting
-	}
-
-	field := parts[0]
-	direction := parts[1]
-
-	sort.Slice(events, func(i, j int) bool {
-		var less bool
+// sortKey is a way to sort the index, selected with sort_by=<key>_<direction>.
+type sortKey struct {
+	Key   string
+	Label string
+	// compare orders two events ascending, text being compared with coll.
+	// Ties are broken by name, then slug.
+	compare func(coll *collate.Collator, a, b Sortable) int
+	// Directions offered for the key, the first being its default
+	Directions []string
+}
 
-		switch field {
-		case "name":
-			less = strings.ToLower(events[i].GetName()) < strings.ToLower(events[j].GetName())
-		case "category":
-			less = strings.ToLower(events[i].GetCategory()) < strings.ToLower(events[j].GetCategory())
-		case "year":
-			// Extract first year from years string for comparison
-			yearI := extractFirstYear(events[i].GetYears())
-			yearJ := extractFirstYear(events[j].GetYears())
-			less = yearI < yearJ
-		default:
-			return false // Unknown field
-		}
+// sortKeys is the registry of the sorts offered on the index, in the order
+// of the sort menu.
+var sortKeys = []sortKey{
+	{Key: "name", Label: "Name", compare: func(coll *collate.Collator, a, b Sortable) int {
+		return coll.CompareString(a.GetName(), b.GetName())
+	}, Directions: []string{"asc", "desc"}},
+	{Key: "year", Label: "Year", compare: func(coll *collate.Collator, a, b Sortable) int {
+		return cmp.Compare(a.GetFirstYear(), b.GetFirstYear())
+	}, Directions: []string{"asc", "desc"}},
+	{Key: "category", Label: "Category", compare: func(coll *collate.Collator, a, b Sortable) int {
+		return coll.CompareString(a.GetCategory(), b.GetCategory())
+	}, Directions: []string{"asc", "desc"}},
+	{Key: "country", Label: "Country", compare: func(coll *collate.Collator, a, b Sortable) int {
+		return coll.CompareString(a.GetCountry(), b.GetCountry())
+	}, Directions: []string{"asc", "desc"}},
+	{Key: "approval", Label: "Approval", compare: func(coll *collate.Collator, a, b Sortable) int {
+		return cmp.Compare(a.GetApprovalLevel(), b.GetApprovalLevel())
+	}, Directions: []string{"desc", "asc"}},
+	{Key: "requests", Label: "Number of requests", compare: func(coll *collate.Collator, a, b Sortable) int {
+		return cmp.Compare(a.GetRequestCount(), b.GetRequestCount())
+	}, Directions: []string{"desc", "asc"}},
+	{Key: "updated", Label: "Last updated", compare: func(coll *collate.Collator, a, b Sortable) int {
+		return a.GetLastUpdated().Compare(b.GetLastUpdated())
+	}, Directions: []string{"desc"}},
+}
 
-		// Reverse if descending
-		if direction == "desc" {
-			return !less
+// supportedSorts lists every key and direction, for the sort menu.
+func supportedSorts() []viewmodel.SupportedSort {
+	var sorts []viewmodel.SupportedSort
+	for _, k := range sortKeys {
+		for _, dir := range k.Directions {
+			sorts = append(sorts, viewmodel.SupportedSort{Name: k.Label, Orientation: dir, Slug: k.Key + "_" + dir})
 		}
-		return less
-	})
+	}
+	return sorts
 }
 
-func extractFirstYear(years string) int {
-	// Normalize dashes
-	normalizedYears := strings.ReplaceAll(years, "–", "-")
-	normalizedYears = strings.ReplaceAll(normalizedYears, "—", "-")
-
-	// Split by comma and take first part
-	parts := strings.Split(normalizedYears, ",")
-	if len(parts) == 0 {
-		return 0
+func supportedSortSlugs() []string {
+	var slugs []string
+	for _, s := range supportedSorts() {
+		slugs = append(slugs, s.Slug)
 	}
+	return slugs
+}
 
-	firstPart := strings.TrimSpace(parts[0])
-
-	// If it's a range, take the start year
-	if strings.Contains(firstPart, "-") {
-		rangeParts := strings.Split(firstPart, "-")
-		firstPart = strings.TrimSpace(rangeParts[0])
+// parseSort resolves sort_by to a registered key and direction. A bare key
+// ("year") gets its default direction. ok is false for unknown sorts.
+func parseSort(sortBy string) (key *sortKey, direction string, ok bool) {
+	name, direction, _ := strings.Cut(sortBy, "_")
+	for i := range sortKeys {
+		if sortKeys[i].Key != name {
+			continue
+		}
+		if direction == "" {
+			direction = sortKeys[i].Directions[0]
+		}
+		if !slices.Contains(sortKeys[i].Directions, direction) {
+			return nil, "", false
+		}
+		return &sortKeys[i], direction, true
 	}
+	return nil, "", false
+}
 
-	// Convert to int
-	year, err := strconv.Atoi(firstPart)
-	if err != nil {
-		return 0
+// applySorting sorts events by sortBy, which must be valid (see parseSort),
+// comparing text with coll. The order is deterministic: ties are broken by
+// name, then slug, and the sort is stable.
+func applySorting[T Sortable](events []T, sortBy string, coll *collate.Collator) {
+	key, direction, ok := parseSort(sortBy)
+	if !ok {
+		return
 	}
-
-	return year
+	slices.SortStableFunc(events, func(a, b T) int {
+		c := key.compare(coll, a, b)
+		if direction == "desc" {
+			c = -c
+		}
+		return cmp.Or(c,
+			coll.CompareString(a.GetName(), b.GetName()),
+			cmp.Compare(a.GetSlug(), b.GetSlug()))
+	})
 }
@@ -2,11 +2,78 @@ package main
 
 import (
 	"testing"
+	"time"
+
+	"marianapparitions/model"
+	"marianapparitions/viewmodel"
+
+	"golang.org/x/text/language"
 )
 
-func TestExtractFirstYear(t *testing.T) {
-	extracted := extractFirstYear("2026")
-	if extracted != 2026 {
-		t.Errorf("Wrong value: %v", extracted)
+func TestApplySorting(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	event := func(name, years string, approval string, updated int) *viewmodel.EventViewModel {
+		return viewmodel.NewEventVM(&model.Event{
+			Name:   name,
+			Years:  years,
+			SlugDB: name,
+			Blocks: []model.EventBlock{{AuthorityPosition: approval, UpdatedAt: day(updated)}},
+		})
+	}
+	names := func(events []*viewmodel.EventViewModel) []string {
+		var n []string
+		for _, e := range events {
+			n = append(n, e.Name)
+		}
+		return n
+	}
+
+	events := []*viewmodel.EventViewModel{
+		event("Medjugorje", "1981-present", "curatur", 3),
+		event("Akita", "1973-1981", "approved", 1),
+		event("Fatima", "1917", "approved", 2),
+		event("Garabandal", "1961-1965", "", 4),
+	}
+	for sortBy, want := range map[string][]string{
+		"year_asc":      {"Fatima", "Garabandal", "Akita", "Medjugorje"},
+		"year_desc":     {"Medjugorje", "Akita", "Garabandal", "Fatima"},
+		"approval_desc": {"Akita", "Fatima", "Medjugorje", "Garabandal"}, // ties by name
+		"updated_desc":  {"Garabandal", "Medjugorje", "Fatima", "Akita"},
+	} {
+		applySorting(events, sortBy, newCollator(language.English))
+		got := names(events)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %v, want %v", sortBy, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	if key, dir, ok := parseSort("requests"); !ok || key.Key != "requests" || dir != "desc" {
+		t.Errorf("a bare key should get its default direction, got %v %q %v", key, dir, ok)
+	}
+	for _, invalid := range []string{"bogus_asc", "updated_asc", "name_sideways", ""} {
+		if _, _, ok := parseSort(invalid); ok {
+			t.Errorf("parseSort(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestApplySortingCollation(t *testing.T) {
+	var events []*viewmodel.EventViewModel
+	for _, name := range []string{"Zeitoun", "Šiluva", "Ðurđevac", "akita", "Fatima"} {
+		events = append(events, viewmodel.NewEventVM(&model.Event{Name: name}))
+	}
+
+	applySorting(events, "name_asc", newCollator(language.English))
+
+	want := []string{"akita", "Ðurđevac", "Fatima", "Šiluva", "Zeitoun"}
+	for i, e := range events {
+		if e.Name != want[i] {
+			t.Fatalf("got %s at %d, want the order %v", e.Name, i, want)
+		}
 	}
 }
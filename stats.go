@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"marianapparitions/stats"
+	"marianapparitions/viewmodel"
+)
+
+// handleStats shows statistics about all the events, as a page on /stats
+// and as JSON on /stats.json.
+func handleStats(w http.ResponseWriter, r *http.Request) {
+	events, err := indexEvents.Get(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s := stats.Compute(events)
+
+	if strings.HasSuffix(r.URL.Path, ".json") {
+		writeJSON(w, r, http.StatusOK, s)
+		return
+	}
+
+	recordPageView(r.Context(), "/stats", "")
+	renderTemplate(w, r, "stats.html", &viewmodel.StatsViewModel{Stats: s})
+}
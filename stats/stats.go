@@ -0,0 +1,199 @@
+// Package stats summarizes the apparitions: counts by century, country,
+// category and church verdict, and the most common kinds of requests.
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"marianapparitions/model"
+)
+
+// Count is the number of events (or requests) with a label.
+type Count struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// ChurchVerdicts counts the events by the position a church took on them.
+type ChurchVerdicts struct {
+	Church    string  `json:"church"`
+	Positions []Count `json:"positions"`
+}
+
+// Period counts approved and unrecognized events that started in a century.
+type Period struct {
+	Label        string `json:"label"`
+	Century      int    `json:"century"`
+	Approved     int    `json:"approved"`
+	Unrecognized int    `json:"unrecognized"`
+}
+
+type Stats struct {
+	Events     int     `json:"events"`
+	ByCentury  []Count `json:"by_century"`
+	ByCountry  []Count `json:"by_country"`
+	ByCategory []Count `json:"by_category"`
+	// One entry per model.Churches, "none" counting events without a verdict
+	VerdictsByChurch []ChurchVerdicts `json:"verdicts_by_church"`
+	// Approved (by any church) versus unrecognized, per century
+	ApprovalOverTime []Period `json:"approval_over_time"`
+	// The most common kinds of Mary's requests, see RequestCategories
+	TopRequestCategories []Count `json:"top_request_categories"`
+}
+
+// Unknown labels events without a country, category or parseable years.
+const Unknown = "Unknown"
+
+// NoVerdict labels events a church didn't take a position on.
+const NoVerdict = "none"
+
+// TopRequests is the number of request categories kept.
+const TopRequests = 10
+
+// RequestCategories classify the free-text requests by keyword. A request
+// can fall in several categories, or in "Other".
+var RequestCategories = []struct {
+	Name     string
+	Keywords []string
+}{
+	{"Rosary", []string{"rosary"}},
+	{"Prayer", []string{"pray"}},
+	{"Penance and sacrifice", []string{"penance", "sacrifice", "fast", "mortif"}},
+	{"Conversion", []string{"conver", "repent", "sinner"}},
+	{"Build a church or chapel", []string{"chapel", "church", "shrine", "basilica", "build"}},
+	{"Consecration", []string{"consecrat"}},
+	{"Processions and pilgrimages", []string{"procession", "pilgrim"}},
+	{"Mass and Communion", []string{"communion", "mass", "eucharist"}},
+	{"Medals, scapulars and images", []string{"medal", "scapular", "image", "picture"}},
+}
+
+// Compute summarizes events. They must have their Blocks and Requests loaded.
+func Compute(events []model.Event) Stats {
+	s := Stats{Events: len(events)}
+
+	centuries := make(map[int]int)
+	periods := make(map[int]*Period)
+	countries := make(map[string]int)
+	categories := make(map[string]int)
+	verdicts := make([]map[string]int, len(model.Churches))
+	for i := range verdicts {
+		verdicts[i] = make(map[string]int)
+	}
+	requests := make(map[string]int)
+
+	for i := range events {
+		e := &events[i]
+		countries[orUnknown(e.Country)]++
+		categories[orUnknown(e.Category)]++
+
+		century := 0
+		if ranges, _ := e.ParseYears(); len(ranges) > 0 && ranges[0].Start > 0 {
+			century = model.Century(ranges[0].Start)
+		}
+		centuries[century]++
+		p := periods[century]
+		if p == nil {
+			p = &Period{Label: centuryLabel(century), Century: century}
+			periods[century] = p
+		}
+		if e.IsApproved() {
+			p.Approved++
+		} else {
+			p.Unrecognized++
+		}
+
+		for j, church := range model.Churches {
+			if b := e.Verdict(church); b != nil {
+				verdicts[j][b.AuthorityPosition]++
+			} else {
+				verdicts[j][NoVerdict]++
+			}
+		}
+
+		for _, r := range e.Requests {
+			for _, c := range classifyRequest(r.Request) {
+				requests[c]++
+			}
+		}
+	}
+
+	for _, century := range sortedKeys(centuries) {
+		s.ByCentury = append(s.ByCentury, Count{Label: centuryLabel(century), Count: centuries[century]})
+		s.ApprovalOverTime = append(s.ApprovalOverTime, *periods[century])
+	}
+	s.ByCountry = byCount(countries)
+	s.ByCategory = byCount(categories)
+	for i, church := range model.Churches {
+		s.VerdictsByChurch = append(s.VerdictsByChurch, ChurchVerdicts{Church: church, Positions: byCount(verdicts[i])})
+	}
+	s.TopRequestCategories = byCount(requests)
+	if len(s.TopRequestCategories) > TopRequests {
+		s.TopRequestCategories = s.TopRequestCategories[:TopRequests]
+	}
+	return s
+}
+
+func classifyRequest(request string) []string {
+	request = strings.ToLower(request)
+	var matches []string
+	for _, c := range RequestCategories {
+		for _, k := range c.Keywords {
+			if strings.Contains(request, k) {
+				matches = append(matches, c.Name)
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return []string{"Other"}
+	}
+	return matches
+}
+
+func orUnknown(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return Unknown
+	}
+	return s
+}
+
+// centuryLabel returns e.g. "20th century", 0 being events without years.
+func centuryLabel(century int) string {
+	if century == 0 {
+		return Unknown
+	}
+	return model.CenturyName(century)
+}
+
+// sortedKeys returns the centuries in chronological order, unknown last.
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b int) int {
+		return cmp.Or(cmp.Compare(boolInt(a == 0), boolInt(b == 0)), cmp.Compare(a, b))
+	})
+	return keys
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// byCount returns the counts, largest first, ties by label.
+func byCount(m map[string]int) []Count {
+	counts := make([]Count, 0, len(m))
+	for label, n := range m {
+		counts = append(counts, Count{Label: label, Count: n})
+	}
+	slices.SortFunc(counts, func(a, b Count) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Label, b.Label))
+	})
+	return counts
+}
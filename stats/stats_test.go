@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"testing"
+
+	"marianapparitions/model"
+)
+
+func TestCompute(t *testing.T) {
+	events := []model.Event{
+		{
+			Category: "Apparition", Country: "Portugal", Years: "1917",
+			Blocks:   []model.EventBlock{{ChurchAuthority: "Catholic Church", AuthorityPosition: "approved"}},
+			Requests: []model.Request{{Request: "Pray the Rosary every day"}, {Request: "Build a chapel here"}},
+		},
+		{
+			Category: "Apparition", Country: "Bosnia and Herzegovina", Years: "1981-present",
+			Blocks:   []model.EventBlock{{ChurchAuthority: "Catholic Church", AuthorityPosition: "nihil_obstat"}},
+			Requests: []model.Request{{Request: "Fast on Wednesdays and Fridays"}},
+		},
+		{Category: "Apparition", Years: "unknown"},
+	}
+
+	s := Compute(events)
+
+	if s.Events != 3 {
+		t.Errorf("Events = %d, want 3", s.Events)
+	}
+	if len(s.ByCentury) != 2 || s.ByCentury[0] != (Count{"20th century", 2}) || s.ByCentury[1].Label != Unknown {
+		t.Errorf("ByCentury = %v, want the 20th century then unknown years", s.ByCentury)
+	}
+	if p := s.ApprovalOverTime[0]; p.Approved != 2 || p.Unrecognized != 0 {
+		t.Errorf("20th century approval = %+v, want approved and nihil_obstat both approved", p)
+	}
+	catholic := s.VerdictsByChurch[0]
+	if catholic.Church != "Catholic" || len(catholic.Positions) != 3 {
+		t.Errorf("Catholic verdicts = %+v, want approved, nihil_obstat and none", catholic)
+	}
+	want := map[string]int{"Rosary": 1, "Prayer": 1, "Build a church or chapel": 1, "Penance and sacrifice": 1}
+	for _, c := range s.TopRequestCategories {
+		if want[c.Label] != c.Count {
+			t.Errorf("request category %q counted %d times, want %d", c.Label, c.Count, want[c.Label])
+		}
+	}
+}
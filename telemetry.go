@@ -3,12 +3,19 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"time"
+
+	"marianapparitions/config"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
@@ -18,7 +25,16 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-func initTelemetry(ctx context.Context) (shutdown func(context.Context) error, err error) {
+// telemetryFlushTimeout bounds the flushing of telemetry on exit. It comes
+// on top of shutdown_timeout: the service manager must give the app at
+// least their sum to stop (TimeoutStopSec in the systemd unit).
+const telemetryFlushTimeout = 5 * time.Second
+
+// initTelemetry installs the global trace, metric and log providers for
+// mode. With config.TelemetryOff nothing is installed and the global no-op
+// providers stay in place. sampleRatio is the fraction of new traces to
+// record; spans with a parent follow the parent's decision.
+func initTelemetry(ctx context.Context, mode string, sampleRatio float64) (shutdown func(context.Context) error, err error) {
 	var shutdownFuncs []func(context.Context) error
 
 	shutdown = func(ctx context.Context) error {
@@ -29,6 +45,10 @@ func initTelemetry(ctx context.Context) (shutdown func(context.Context) error, e
 		return errors.Join(errs...)
 	}
 
+	if mode == config.TelemetryOff {
+		return shutdown, nil
+	}
+
 	serviceName := os.Getenv("OTEL_SERVICE_NAME")
 	if serviceName == "" {
 		serviceName = "marianapparitions"
@@ -50,37 +70,31 @@ func initTelemetry(ctx context.Context) (shutdown func(context.Context) error, e
 		propagation.Baggage{},
 	))
 
-	// Trace exporter
-	traceExporter, err := otlptracehttp.New(ctx)
+	exp, err := newExporters(ctx, mode)
 	if err != nil {
 		return shutdown, err
 	}
+
+	// Traces
 	tp := trace.NewTracerProvider(
-		trace.WithBatcher(traceExporter),
+		trace.WithBatcher(exp.trace),
+		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(sampleRatio))),
 		trace.WithResource(res),
 	)
 	shutdownFuncs = append(shutdownFuncs, tp.Shutdown)
 	otel.SetTracerProvider(tp)
 
-	// Metric exporter
-	metricExporter, err := otlpmetrichttp.New(ctx)
-	if err != nil {
-		return shutdown, err
-	}
+	// Metrics
 	mp := metric.NewMeterProvider(
-		metric.WithReader(metric.NewPeriodicReader(metricExporter)),
+		metric.WithReader(metric.NewPeriodicReader(exp.metric, metric.WithInterval(exp.metricInterval))),
 		metric.WithResource(res),
 	)
 	shutdownFuncs = append(shutdownFuncs, mp.Shutdown)
 	otel.SetMeterProvider(mp)
 
-	// Log exporter
-	logExporter, err := otlploghttp.New(ctx)
-	if err != nil {
-		return shutdown, err
-	}
+	// Logs
 	lp := log.NewLoggerProvider(
-		log.WithProcessor(log.NewBatchProcessor(logExporter)),
+		log.WithProcessor(log.NewBatchProcessor(exp.log)),
 		log.WithResource(res),
 	)
 	shutdownFuncs = append(shutdownFuncs, lp.Shutdown)
@@ -88,3 +102,45 @@ func initTelemetry(ctx context.Context) (shutdown func(context.Context) error, e
 
 	return shutdown, nil
 }
+
+type exporters struct {
+	trace          trace.SpanExporter
+	metric         metric.Exporter
+	metricInterval time.Duration
+	log            log.Exporter
+}
+
+// newExporters creates the exporters of a mode. The OTLP ones are
+// configured with the standard OTEL_EXPORTER_OTLP_* variables.
+func newExporters(ctx context.Context, mode string) (exporters, error) {
+	var exp exporters
+	var err error
+	switch mode {
+	case config.TelemetryStdout:
+		if exp.trace, err = stdouttrace.New(stdouttrace.WithPrettyPrint()); err != nil {
+			return exp, err
+		}
+		if exp.metric, err = stdoutmetric.New(stdoutmetric.WithPrettyPrint()); err != nil {
+			return exp, err
+		}
+		// Short enough to see the effect of a few page loads
+		exp.metricInterval = 15 * time.Second
+		if exp.log, err = stdoutlog.New(stdoutlog.WithPrettyPrint()); err != nil {
+			return exp, err
+		}
+	case config.TelemetryOTLP:
+		if exp.trace, err = otlptracehttp.New(ctx); err != nil {
+			return exp, err
+		}
+		if exp.metric, err = otlpmetrichttp.New(ctx); err != nil {
+			return exp, err
+		}
+		exp.metricInterval = time.Minute
+		if exp.log, err = otlploghttp.New(ctx); err != nil {
+			return exp, err
+		}
+	default:
+		return exp, fmt.Errorf("unknown telemetry mode %q", mode)
+	}
+	return exp, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// renderTemplate parses templates/<name> and executes it with data.
+// Templates are parsed on every request so edits show up without a restart
+// when serving them from disk (dev_dir or templates_dir).
+func renderTemplate(w http.ResponseWriter, r *http.Request, name string, data any) {
+	start := time.Now()
+	funcs := template.FuncMap{
+		"asset":   staticAssets.URL,
+		"baseURL": func() string { return cfg.BaseURL },
+	}
+	tmpl, err := template.New(name).Funcs(funcs).ParseFS(templatesFS, name)
+	if err != nil {
+		recordRender(r.Context(), name, start, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = tmpl.Execute(w, data)
+	recordRender(r.Context(), name, start, err)
+}
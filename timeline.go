@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"marianapparitions/viewmodel"
+)
+
+// handleTimeline plots the events matching the index filters by century.
+func handleTimeline(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseIndexFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, err := loadCategories(r.Context(), filters.Locale)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	events, err := filterEvents(r.Context(), filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	recordIndexQuery(r.Context(), "/timeline", filters, categories, len(events))
+
+	viewModel := viewmodel.NewTimelineViewModel(events, time.Now().Year())
+	viewModel.Categories = categories
+	viewModel.SelectedCategories = filters.SelectedCategories
+	viewModel.StartYear = filters.StartYear
+	viewModel.EndYear = filters.EndYear
+	viewModel.RawQuery = r.URL.Query().Encode()
+	recordPageView(r.Context(), "/timeline", "")
+	renderTemplate(w, r, "timeline.html", viewModel)
+}
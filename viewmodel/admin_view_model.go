@@ -0,0 +1,44 @@
+package viewmodel
+
+import (
+	"marianapparitions/model"
+)
+
+// AdminIndexViewModel lists the events to edit.
+type AdminIndexViewModel struct {
+	Events []model.Event
+}
+
+// AdminEventViewModel is the edit page of an event and of its blocks,
+// requests and sources, or the page creating an event.
+type AdminEventViewModel struct {
+	Event model.Event
+	// Problems of the record last submitted, which wasn't saved
+	Errors model.ValidationError
+	// What the errors are about, e.g. "block #3" or "new source"
+	ErrorsAbout string
+	// Forms adding a block, request or source, filled again after errors
+	NewBlock   model.EventBlock
+	NewRequest model.Request
+	NewSource  model.Source
+}
+
+func (vm *AdminEventViewModel) IsNew() bool {
+	return vm.Event.ID == 0
+}
+
+// BlockForm is what the fields of a block's form show.
+func (vm *AdminEventViewModel) BlockForm(b model.EventBlock) *AdminBlockForm {
+	return &AdminBlockForm{EventBlock: b, AuthorityPositions: append([]string{""}, model.AuthorityPositions...)}
+}
+
+// IsLastBlock reports whether the i-th block can't move down.
+func (vm *AdminEventViewModel) IsLastBlock(i int) bool {
+	return i == len(vm.Event.Blocks)-1
+}
+
+type AdminBlockForm struct {
+	model.EventBlock
+	// Choices of the authority position, "" first for blocks that aren't a verdict
+	AuthorityPositions []string
+}
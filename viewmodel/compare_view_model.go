@@ -0,0 +1,93 @@
+package viewmodel
+
+import (
+	"strings"
+
+	"marianapparitions/model"
+)
+
+// CompareViewModel shows events side by side, one column per event.
+type CompareViewModel struct {
+	Events []*EventViewModel
+	// Slugs asked for that don't match any event
+	Missing  []string
+	Churches []string
+	// Block titles that at least two of the events have, in order of appearance
+	BlockTitles []string
+	// All events, to pick the ones to compare
+	Choices []*EventViewModel
+	// Normalized requests made in more than one of the events
+	shared map[string]bool
+}
+
+func NewCompareViewModel(events []*EventViewModel) *CompareViewModel {
+	vm := &CompareViewModel{Events: events, Churches: model.Churches, shared: make(map[string]bool)}
+
+	requestCounts := make(map[string]int)
+	titleCounts := make(map[string]int)
+	var titles []string
+	for _, e := range events {
+		seen := make(map[string]bool)
+		for _, r := range e.Requests {
+			key := normalizeRequest(r.Request)
+			if !seen[key] {
+				seen[key] = true
+				requestCounts[key]++
+			}
+		}
+		seenTitles := make(map[string]bool)
+		for _, b := range e.Blocks {
+			if b.Title == "" || b.Title == "Excerpt" || seenTitles[b.Title] {
+				continue
+			}
+			seenTitles[b.Title] = true
+			if titleCounts[b.Title] == 0 {
+				titles = append(titles, b.Title)
+			}
+			titleCounts[b.Title]++
+		}
+	}
+	for key, n := range requestCounts {
+		if n > 1 {
+			vm.shared[key] = true
+		}
+	}
+	for _, t := range titles {
+		if titleCounts[t] > 1 {
+			vm.BlockTitles = append(vm.BlockTitles, t)
+		}
+	}
+	return vm
+}
+
+// IsShared reports whether another compared event has the same request.
+func (vm *CompareViewModel) IsShared(request string) bool {
+	return vm.shared[normalizeRequest(request)]
+}
+
+// IsCompared reports whether the event with this slug is one of the columns.
+func (vm *CompareViewModel) IsCompared(slug string) bool {
+	for _, e := range vm.Events {
+		if e.Slug() == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeRequest ignores case, spacing and final punctuation, so
+// "Pray the Rosary." matches "pray the rosary".
+func normalizeRequest(request string) string {
+	request = strings.Join(strings.Fields(strings.ToLower(request)), " ")
+	return strings.TrimRight(request, ".!;,")
+}
+
+// BlockTitled returns the first block with this title, or nil.
+func (vm *EventViewModel) BlockTitled(title string) *model.EventBlock {
+	for i, block := range vm.Event.Blocks {
+		if block.Title == title {
+			return &vm.Event.Blocks[i]
+		}
+	}
+	return nil
+}
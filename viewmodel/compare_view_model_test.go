@@ -0,0 +1,30 @@
+package viewmodel
+
+import (
+	"slices"
+	"testing"
+
+	"marianapparitions/model"
+)
+
+func TestCompareViewModel(t *testing.T) {
+	fatima := &EventViewModel{Event: model.Event{
+		Requests: []model.Request{{Request: "Pray the Rosary every day."}, {Request: "Consecrate Russia"}},
+		Blocks:   []model.EventBlock{{Title: "Messages"}, {Title: "Miracles"}, {Title: "Excerpt"}},
+	}}
+	akita := &EventViewModel{Event: model.Event{
+		Requests: []model.Request{{Request: "pray  the rosary every day"}},
+		Blocks:   []model.EventBlock{{Title: "Miracles"}, {Title: "Excerpt"}},
+	}}
+
+	vm := NewCompareViewModel([]*EventViewModel{fatima, akita})
+	if !vm.IsShared("Pray the Rosary every day.") {
+		t.Error("the Rosary request should be shared despite case, spacing and punctuation")
+	}
+	if vm.IsShared("Consecrate Russia") {
+		t.Error("a request of a single event isn't shared")
+	}
+	if !slices.Equal(vm.BlockTitles, []string{"Miracles"}) {
+		t.Errorf("BlockTitles = %q, want only the titles both events have", vm.BlockTitles)
+	}
+}
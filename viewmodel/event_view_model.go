@@ -1,22 +1,87 @@
 package viewmodel
 
 import (
+	"encoding/json"
 	//"fmt"
+	"math"
 	"strings"
+	"time"
 
 	"marianapparitions/model"
+	"marianapparitions/related"
 )
 
 type EventViewModel struct {
 	model.Event
+	// Most similar events, only on the event's page
+	Related []*RelatedEvent
+	// The index list the event's page was opened from, only on that page
+	List *ListNavigation
 }
-func (e *EventViewModel) GetName() string     { return e.Name }
-func (e *EventViewModel) GetCategory() string { return e.Category }
-func (e *EventViewModel) GetYears() string    { return e.Years }
 
+// RelatedEvent is an event recommended from another one, see package related.
+type RelatedEvent struct {
+	*EventViewModel
+	Score   float64
+	Reasons []string
+}
+
+func NewRelatedEvents(matches []related.Match) []*RelatedEvent {
+	var events []*RelatedEvent
+	for _, m := range matches {
+		events = append(events, &RelatedEvent{NewEventVM(m.Event), m.Score, m.Reasons})
+	}
+	return events
+}
+
+// MarshalJSON only keeps what identifies the event.
+func (r *RelatedEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Slug    string   `json:"slug"`
+		Name    string   `json:"name"`
+		Years   string   `json:"years"`
+		URL     string   `json:"url"`
+		Score   float64  `json:"score"`
+		Reasons []string `json:"reasons"`
+	}{r.Slug(), r.Name, r.Years, "/" + r.Slug(), math.Round(r.Score*100) / 100, r.Reasons})
+}
+
+func (e *EventViewModel) GetName() string           { return e.Name }
+func (e *EventViewModel) GetCategory() string       { return e.Category }
+func (e *EventViewModel) GetYears() string          { return e.Years }
+func (e *EventViewModel) GetCountry() string        { return e.Country }
+func (e *EventViewModel) GetSlug() string           { return e.Slug() }
+func (e *EventViewModel) GetFirstYear() int         { return e.FirstYear() }
+func (e *EventViewModel) GetApprovalLevel() int     { return e.ApprovalLevel() }
+func (e *EventViewModel) GetRequestCount() int      { return len(e.Requests) }
+func (e *EventViewModel) GetLastUpdated() time.Time { return e.LastUpdated() }
+
+// MarshalJSON adds what the pages compute from the event to its fields.
+func (vm *EventViewModel) MarshalJSON() ([]byte, error) {
+	approvedBy := []string{}
+	for _, church := range model.Churches {
+		if authority := vm.GetApproverChurch(church); authority != "" {
+			approvedBy = append(approvedBy, authority)
+		}
+	}
+	return json.Marshal(struct {
+		*model.Event
+		URL          string          `json:"url"`
+		WikipediaURL string          `json:"wikipedia_url,omitempty"`
+		ApprovedBy   []string        `json:"approved_by"`
+		Related      []*RelatedEvent `json:"related,omitempty"`
+		List         *ListNavigation `json:"list,omitempty"`
+	}{&vm.Event, "/" + vm.Slug(), vm.WikipediaArticle(), approvedBy, vm.Related, vm.List})
+}
+
+// WikipediaArticle returns the link to the event's Wikipedia article, in
+// the language of its title, or "" if it has none.
+func (vm *EventViewModel) WikipediaArticle() string {
+	return vm.WikipediaURL(model.WikipediaLanguage)
+}
 
 func NewEventVM(event *model.Event) *EventViewModel {
-	return &EventViewModel{*event}
+	return &EventViewModel{Event: *event}
 }
 
 func (vm *EventViewModel) HasAnyApproval() bool {
@@ -25,7 +90,7 @@ func (vm *EventViewModel) HasAnyApproval() bool {
 
 func (vm *EventViewModel) GetApproverChurch(churchNameSubstr string) string {
 	for _, block := range vm.Event.Blocks {
-		if strings.Contains(block.ChurchAuthority, churchNameSubstr) && block.AuthorityPosition == "approved" {
+		if strings.Contains(block.ChurchAuthority, churchNameSubstr) && block.IsApproval() {
 			return block.ChurchAuthority
 		}
 	}
@@ -1,13 +1,13 @@
 package viewmodel
 
 import (
-	"fmt"
+	"html/template"
 )
 
 type SupportedSort struct {
-	Name string
-	Orientation string
-	Slug string
+	Name        string `json:"name"`
+	Orientation string `json:"orientation"`
+	Slug        string `json:"slug"`
 }
 
 type QueryString struct {
@@ -15,22 +15,24 @@ type QueryString struct {
 	Value string
 }
 
+// IndexViewModel is the index page, and its JSON (see render in package main).
 type IndexViewModel struct {
-	Events             []*EventViewModel
-	Categories         []string
-	SelectedCategories map[string]bool
-	StartYear          int
-	EndYear            int
-	SupportedSorts     []SupportedSort
-	CurrentSort        string
-	FilterQuery        map[string]string
+	Events             []*EventViewModel `json:"events"`
+	Categories         []string          `json:"categories"`
+	SelectedCategories map[string]bool   `json:"selected_categories"`
+	StartYear          int               `json:"start_year,omitempty"`
+	EndYear            int               `json:"end_year,omitempty"`
+	SupportedSorts     []SupportedSort   `json:"sorts"`
+	CurrentSort        string            `json:"sort"`
+	InvalidSort        string            `json:"invalid_sort,omitempty"` // Unknown sort_by that was replaced by CurrentSort
+	FilterQuery        map[string]string `json:"-"`
+	RawQuery           string            `json:"-"` // Encoded query string of the current request
 }
 
 // SortHref generates a slice of QueryString's
 // It also makes sure you won't get a duplicate sort_by key
 // if one was passed in the current querystring (through vm.FilterQuery).
 func (vm *IndexViewModel) SortHref(sort SupportedSort) []*QueryString {
-	fmt.Printf("SortHref %o \n", sort)
 	var query []*QueryString
 	if len(vm.FilterQuery) > 0 {
 		for key, value := range vm.FilterQuery {
@@ -55,3 +57,17 @@ func (vm *IndexViewModel) GetSortNameByString(sort string) string {
 	return ""
 }
 
+// ExportHref returns the link to download the current list (filters and
+// sort included) as a file of the given type ("csv" or "xlsx").
+func (vm *IndexViewModel) ExportHref(ext string) template.URL {
+	return vm.WithQuery("/export." + ext)
+}
+
+// WithQuery returns path with the current filters and sort, to link to
+// other views of the same list.
+func (vm *IndexViewModel) WithQuery(path string) template.URL {
+	if vm.RawQuery != "" {
+		path += "?" + vm.RawQuery
+	}
+	return template.URL(path)
+}
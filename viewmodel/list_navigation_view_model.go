@@ -0,0 +1,71 @@
+package viewmodel
+
+import (
+	"encoding/json"
+	"html/template"
+)
+
+// ListNavigation links an event's page to its neighbours in the index list
+// it was opened from, keeping the filters and sort of that list.
+type ListNavigation struct {
+	Previous *EventViewModel
+	Next     *EventViewModel
+	Position int // From 1, 0 when the event isn't in the list
+	Total    int
+	RawQuery string // Encoded query string of the list
+}
+
+// NewListNavigation finds the event in events, the filtered and sorted list
+// of the index for rawQuery.
+func NewListNavigation(events []*EventViewModel, eventID int, rawQuery string) *ListNavigation {
+	nav := &ListNavigation{Total: len(events), RawQuery: rawQuery}
+	for i, e := range events {
+		if e.ID != eventID {
+			continue
+		}
+		nav.Position = i + 1
+		if i > 0 {
+			nav.Previous = events[i-1]
+		}
+		if i < len(events)-1 {
+			nav.Next = events[i+1]
+		}
+		break
+	}
+	return nav
+}
+
+// BackHref returns the link to the index with the list's filters and sort.
+func (nav *ListNavigation) BackHref() template.URL {
+	return nav.withQuery("/")
+}
+
+// Href returns the link to an event of the list, which keeps the list.
+func (nav *ListNavigation) Href(e *EventViewModel) template.URL {
+	return nav.withQuery("/" + e.Slug())
+}
+
+func (nav *ListNavigation) withQuery(path string) template.URL {
+	if nav.RawQuery != "" {
+		path += "?" + nav.RawQuery
+	}
+	return template.URL(path)
+}
+
+// MarshalJSON gives the links rather than the neighbouring events.
+func (nav *ListNavigation) MarshalJSON() ([]byte, error) {
+	var previous, next string
+	if nav.Previous != nil {
+		previous = string(nav.Href(nav.Previous))
+	}
+	if nav.Next != nil {
+		next = string(nav.Href(nav.Next))
+	}
+	return json.Marshal(struct {
+		Back     string `json:"back"`
+		Previous string `json:"previous,omitempty"`
+		Next     string `json:"next,omitempty"`
+		Position int    `json:"position,omitempty"`
+		Total    int    `json:"total"`
+	}{string(nav.BackHref()), previous, next, nav.Position, nav.Total})
+}
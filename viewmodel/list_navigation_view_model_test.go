@@ -0,0 +1,36 @@
+package viewmodel
+
+import (
+	"testing"
+
+	"marianapparitions/model"
+)
+
+func TestNewListNavigation(t *testing.T) {
+	var events []*EventViewModel
+	for i, slug := range []string{"fatima", "lourdes", "akita"} {
+		events = append(events, &EventViewModel{Event: model.Event{ID: i + 1, SlugDB: slug}})
+	}
+	query := "category=Apparition&sort_by=year_asc"
+
+	nav := NewListNavigation(events, 2, query)
+	if nav.Position != 2 || nav.Total != 3 {
+		t.Errorf("Position = %d of %d, want 2 of 3", nav.Position, nav.Total)
+	}
+	if nav.Previous != events[0] || nav.Next != events[2] {
+		t.Errorf("Previous, Next = %v, %v, want fatima and akita", nav.Previous, nav.Next)
+	}
+	if got, want := nav.Href(nav.Next), "/akita?"+query; string(got) != want {
+		t.Errorf("Href(Next) = %q, want %q", got, want)
+	}
+	if got := nav.BackHref(); string(got) != "/?"+query {
+		t.Errorf("BackHref() = %q, want the index with the same query", got)
+	}
+
+	if nav := NewListNavigation(events, 1, ""); nav.Previous != nil || nav.Next != events[1] || nav.BackHref() != "/" {
+		t.Errorf("first of the list: %+v", nav)
+	}
+	if nav := NewListNavigation(events, 42, query); nav.Position != 0 || nav.Previous != nil || nav.Next != nil {
+		t.Errorf("event not in the list: %+v", nav)
+	}
+}
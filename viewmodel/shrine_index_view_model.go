@@ -0,0 +1,9 @@
+package viewmodel
+
+import "marianapparitions/model"
+
+type ShrineIndexViewModel struct {
+	Shrines []model.Shrine
+	// OnlyRequested limits the list to shrines built at Mary's explicit request
+	OnlyRequested bool
+}
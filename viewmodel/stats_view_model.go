@@ -0,0 +1,49 @@
+package viewmodel
+
+import "marianapparitions/stats"
+
+type StatsViewModel struct {
+	stats.Stats
+}
+
+// StatsBar is one bar of a horizontal bar chart.
+type StatsBar struct {
+	Label   string
+	Count   int
+	Percent float64 // of the largest count, for the bar width
+}
+
+// Bars scales counts for a bar chart.
+func (vm *StatsViewModel) Bars(counts []stats.Count) []StatsBar {
+	largest := 0
+	for _, c := range counts {
+		largest = max(largest, c.Count)
+	}
+	bars := make([]StatsBar, len(counts))
+	for i, c := range counts {
+		bars[i] = StatsBar{Label: c.Label, Count: c.Count}
+		if largest > 0 {
+			bars[i].Percent = float64(c.Count) * 100 / float64(largest)
+		}
+	}
+	return bars
+}
+
+// ApprovalShare is a stacked bar of approved and unrecognized events.
+type ApprovalShare struct {
+	stats.Period
+	ApprovedPercent     float64
+	UnrecognizedPercent float64
+}
+
+func (vm *StatsViewModel) ApprovalShares() []ApprovalShare {
+	shares := make([]ApprovalShare, len(vm.ApprovalOverTime))
+	for i, p := range vm.ApprovalOverTime {
+		shares[i] = ApprovalShare{Period: p}
+		if total := p.Approved + p.Unrecognized; total > 0 {
+			shares[i].ApprovedPercent = float64(p.Approved) * 100 / float64(total)
+			shares[i].UnrecognizedPercent = 100 - shares[i].ApprovedPercent
+		}
+	}
+	return shares
+}
@@ -0,0 +1,188 @@
+package viewmodel
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"slices"
+	"strconv"
+
+	"marianapparitions/model"
+)
+
+// Layout of the timeline SVGs, in pixels
+const (
+	TimelineWidth      = 900
+	TimelineLabelWidth = 260 // event names, left of the plot
+	timelineRowHeight  = 22
+	timelineBarHeight  = 14
+	timelineAxisHeight = 24
+	timelineYearWidth  = float64(TimelineWidth-TimelineLabelWidth-10) / 100
+)
+
+// timelinePalette colours the categories, in alphabetical order.
+var timelinePalette = []string{"#5a3e85", "#2e7d6b", "#c0622b", "#2f5f9e", "#a83262", "#7a7a2e", "#5d5d5d"}
+
+// TimelineViewModel plots events on one horizontal axis per century.
+type TimelineViewModel struct {
+	Centuries []*TimelineCentury
+	Legend    []TimelineLegendItem
+	// Events whose years can't be plotted
+	Unplaced []*EventViewModel
+
+	// Filter form, as on the index
+	Categories         []string
+	SelectedCategories map[string]bool
+	StartYear          int
+	EndYear            int
+	RawQuery           string // Encoded query string of the current request
+}
+
+// IndexHref links to the index with the same filters.
+func (vm *TimelineViewModel) IndexHref() template.URL {
+	if vm.RawQuery == "" {
+		return "/"
+	}
+	return template.URL("/?" + vm.RawQuery)
+}
+
+type TimelineCentury struct {
+	Number int    // 20 for 1901-2000
+	Label  string // "20th century"
+	Start  int    // first year
+	Height int
+	Ticks  []TimelineTick
+	Rows   []*TimelineRow
+}
+
+type TimelineTick struct {
+	X     float64
+	Label string
+}
+
+// TimelineRow is one event within a century.
+type TimelineRow struct {
+	Event *EventViewModel
+	Y     int // top of the row
+	BarY  int
+	TextY int // baseline of the event name
+	Color string
+	Bars  []TimelineBar
+}
+
+type TimelineBar struct {
+	X, Width float64
+	// The range goes on past the end of the axis: "present", or a later century
+	OpenEnd bool
+	// The range started in an earlier century
+	OpenStart bool
+	// Points of the arrow drawn after open-ended bars
+	Arrow string
+	Title string
+}
+
+type TimelineLegendItem struct {
+	Category string
+	Color    string
+}
+
+// NewTimelineViewModel groups the parsed year ranges of events by century.
+// Ongoing ("present") ranges end at the year now.
+func NewTimelineViewModel(events []*EventViewModel, now int) *TimelineViewModel {
+	vm := &TimelineViewModel{}
+
+	var categories []string
+	for _, e := range events {
+		if !slices.Contains(categories, e.Category) {
+			categories = append(categories, e.Category)
+		}
+	}
+	slices.Sort(categories)
+	colors := make(map[string]string)
+	for i, c := range categories {
+		colors[c] = timelinePalette[i%len(timelinePalette)]
+		vm.Legend = append(vm.Legend, TimelineLegendItem{Category: c, Color: colors[c]})
+	}
+
+	centuries := make(map[int]*TimelineCentury)
+	for _, e := range events {
+		ranges, _ := e.ParseYears()
+		if len(ranges) == 0 {
+			vm.Unplaced = append(vm.Unplaced, e)
+			continue
+		}
+		rows := make(map[int]*TimelineRow)
+		for _, r := range ranges {
+			end := r.End
+			if r.Ongoing {
+				end = max(now, r.Start)
+			}
+			for n := model.Century(r.Start); n <= model.Century(end); n++ {
+				c := centuries[n]
+				if c == nil {
+					c = newTimelineCentury(n)
+					centuries[n] = c
+				}
+				row := rows[n]
+				if row == nil {
+					row = &TimelineRow{Event: e, Color: colors[e.Category]}
+					rows[n] = row
+					c.Rows = append(c.Rows, row)
+				}
+				from, to := max(r.Start, c.Start), min(end, c.Start+99)
+				title := strconv.Itoa(r.Start)
+				if r.Ongoing {
+					title += "-present"
+				} else if r.End != r.Start {
+					title += "-" + strconv.Itoa(r.End)
+				}
+				row.Bars = append(row.Bars, TimelineBar{
+					X:         c.x(from),
+					Width:     float64(to-from+1) * timelineYearWidth,
+					OpenEnd:   r.Ongoing || to < end,
+					OpenStart: from > r.Start,
+					Title:     title,
+				})
+			}
+		}
+	}
+
+	for _, c := range centuries {
+		slices.SortStableFunc(c.Rows, func(a, b *TimelineRow) int {
+			return cmp.Or(cmp.Compare(a.Bars[0].X, b.Bars[0].X), cmp.Compare(a.Event.Name, b.Event.Name))
+		})
+		for i, row := range c.Rows {
+			row.Y = timelineAxisHeight + i*timelineRowHeight
+			row.BarY = row.Y + (timelineRowHeight-timelineBarHeight)/2
+			row.TextY = row.BarY + timelineBarHeight - 3
+			for j := range row.Bars {
+				if b := &row.Bars[j]; b.OpenEnd {
+					end, mid := b.X+b.Width, float64(row.BarY)+timelineBarHeight/2
+					b.Arrow = fmt.Sprintf("%.1f,%d %.1f,%.1f %.1f,%d", end, row.BarY, end+6, mid, end, row.BarY+timelineBarHeight)
+				}
+			}
+		}
+		c.Height = timelineAxisHeight + len(c.Rows)*timelineRowHeight + 4
+		vm.Centuries = append(vm.Centuries, c)
+	}
+	slices.SortFunc(vm.Centuries, func(a, b *TimelineCentury) int { return cmp.Compare(a.Number, b.Number) })
+	return vm
+}
+
+func newTimelineCentury(n int) *TimelineCentury {
+	c := &TimelineCentury{Number: n, Label: model.CenturyName(n), Start: (n-1)*100 + 1}
+	for year := (c.Start/10 + 1) * 10; year < c.Start+100; year += 10 {
+		c.Ticks = append(c.Ticks, TimelineTick{X: c.x(year), Label: strconv.Itoa(year)})
+	}
+	return c
+}
+
+// x returns the horizontal position of the start of year.
+func (c *TimelineCentury) x(year int) float64 {
+	return TimelineLabelWidth + float64(year-c.Start)*timelineYearWidth
+}
+
+// Constants the template needs
+func (vm *TimelineViewModel) Width() int      { return TimelineWidth }
+func (vm *TimelineViewModel) LabelWidth() int { return TimelineLabelWidth }
+func (vm *TimelineViewModel) BarHeight() int  { return timelineBarHeight }
@@ -0,0 +1,28 @@
+package viewmodel
+
+import (
+	"testing"
+
+	"marianapparitions/model"
+)
+
+func TestTimelineCenturies(t *testing.T) {
+	medjugorje := &EventViewModel{Event: model.Event{Name: "Medjugorje", Category: "Apparition", Years: "1981-present"}}
+	unknown := &EventViewModel{Event: model.Event{Name: "Unknown", Years: "c. 1200"}}
+
+	vm := NewTimelineViewModel([]*EventViewModel{medjugorje, unknown}, 2026)
+
+	if len(vm.Centuries) != 2 || vm.Centuries[0].Label != "20th century" || vm.Centuries[1].Label != "21st century" {
+		t.Fatalf("an ongoing range since 1981 should span the 20th and 21st centuries, got %d centuries", len(vm.Centuries))
+	}
+	first, last := vm.Centuries[0].Rows[0].Bars[0], vm.Centuries[1].Rows[0].Bars[0]
+	if !first.OpenEnd || first.OpenStart {
+		t.Errorf("20th century bar: OpenEnd=%v OpenStart=%v, want it to continue into the next century", first.OpenEnd, first.OpenStart)
+	}
+	if !last.OpenEnd || !last.OpenStart || last.Arrow == "" {
+		t.Errorf("21st century bar: OpenEnd=%v OpenStart=%v, want an open-ended bar with an arrow", last.OpenEnd, last.OpenStart)
+	}
+	if len(vm.Unplaced) != 1 || vm.Unplaced[0] != unknown {
+		t.Errorf("Unplaced = %v, want the event without parseable years", vm.Unplaced)
+	}
+}